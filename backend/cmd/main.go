@@ -2,7 +2,11 @@ package main
 
 import (
 	"Docker_Management/pkg/api"
+	"Docker_Management/pkg/auth"
 	"Docker_Management/pkg/config"
+	"Docker_Management/pkg/db"
+	"Docker_Management/pkg/docker"
+	"context"
 	"log"
 	"net/http"
 )
@@ -11,15 +15,71 @@ func main() {
 	// Load configuration
 	config.LoadConfig()
 
-	// Connect to MongoDB using the loaded MongoURI
-	// db.ConnectDB(config.AppConfig.MongoURI)
+	// Connect to MongoDB using the loaded MongoURI, or keep history in memory without one
+	var history db.HistoryStore
+	if config.AppConfig.MongoURI != "" {
+		db.ConnectDB(config.AppConfig.MongoURI)
+		history = db.NewMongoHistoryStore(db.GetCollection("history"))
+	} else {
+		log.Println("No MONGO_URI set, keeping container history in memory")
+		history = db.NewMemoryHistoryStore(config.AppConfig.HistoryMaxRecords, config.AppConfig.HistoryMaxAge)
+	}
+
+	// Log every mutating API call to the configured sink
+	var audit db.AuditStore
+	switch config.AppConfig.AuditSink {
+	case "mongodb":
+		if config.AppConfig.MongoURI == "" {
+			log.Fatal("AUDIT_SINK=mongodb requires MONGO_URI")
+		}
+		audit = db.NewMongoAuditStore(db.GetCollection("audit"))
+	case "file":
+		fileAudit, err := db.NewFileAuditStore(config.AppConfig.AuditLogFile)
+		if err != nil {
+			log.Fatalf("Failed to open audit log %s: %v", config.AppConfig.AuditLogFile, err)
+		}
+		audit = fileAudit
+	default:
+		log.Fatalf("Unknown AUDIT_SINK %q, expected mongodb or file", config.AppConfig.AuditSink)
+	}
+
+	// Create one shared Docker client per configured host
+	hosts, err := docker.NewHostRegistry(config.AppConfig.DockerHosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer hosts.Close()
+
+	// Load the API users and tokens
+	authenticator, err := auth.NewAuthenticator(config.AppConfig.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !authenticator.Enabled() {
+		log.Println("No users or API tokens configured, the API is open to every client")
+	}
+
+	// Load the rules guarding resources against deletion
+	protection, err := docker.NewProtectionPolicy(config.AppConfig.ProtectionRules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Watch the event stream of every host and record lifecycle events
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := docker.NewEventHub(hosts)
+	go events.Run(ctx)
+	go docker.RecordDaemonEvents(ctx, events, history)
 
 	// Set up routes
 	log.Printf("Starting server on :%s", config.AppConfig.ServerPort)
-	router := api.SetupRouter()
+	jobs := docker.NewJobManager()
+	credentials := docker.NewCredentialStore(config.AppConfig.RegistryCredentials)
+	router := api.SetupRouter(config.AppConfig.HTTP, authenticator, hosts, events, jobs, credentials, protection, history, audit)
 
 	// Start the server
-	log.Printf("Started Server on :%s",config.AppConfig.ServerPort)
+	log.Printf("Started Server on :%s", config.AppConfig.ServerPort)
 	if err := http.ListenAndServe(":"+config.AppConfig.ServerPort, router); err != nil {
 		log.Fatal(err)
 	}
@@ -3,194 +3,468 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"Docker_Management/pkg/docker"
 
 	"github.com/docker/docker/api/types"
 )
 
-// ContainerResponse represents the structure of the container information
+// ContainerResponse represents the structure of the container information,
+// with enough detail for a list view without inspecting each container
 type ContainerResponse struct {
-	ID     string `json:"id"`
-	Image  string `json:"image"`
-	Status string `json:"status"`
+	Host     string             `json:"host"` // Docker host the container runs on
+	ID       string             `json:"id"`
+	Names    []string           `json:"names"` // Without the leading slash
+	Image    string             `json:"image"`
+	Command  string             `json:"command"`
+	Created  string             `json:"created"`
+	State    string             `json:"state"`  // e.g. running or exited
+	Status   string             `json:"status"` // e.g. Up 2 hours
+	Ports    []ContainerPort    `json:"ports"`
+	Labels   map[string]string  `json:"labels"`
+	Mounts   []ContainerMount   `json:"mounts"`
+	Networks []ContainerNetwork `json:"networks"`
+
+	// Sizes in bytes, only computed when the list is requested with size=true
+	SizeRw     *int64 `json:"size_rw,omitempty"`      // Files written by the container
+	SizeRootFs *int64 `json:"size_root_fs,omitempty"` // Image and written files
+
+	// Docker Compose project and service, from the labels compose sets
+	ComposeProject string `json:"compose_project,omitempty"`
+	ComposeService string `json:"compose_service,omitempty"`
 }
 
-func ListContainersHandler(w http.ResponseWriter, r *http.Request) {
-	containers, err := docker.ListContainers()
-	if err != nil {
-		http.Error(w, "Failed to list containers", http.StatusInternalServerError)
-		return
-	}
+// ContainerPort is a port a container exposes, and where it is published
+type ContainerPort struct {
+	IP          string `json:"ip,omitempty"` // Host address the port is published on
+	PrivatePort uint16 `json:"private_port"`
+	PublicPort  uint16 `json:"public_port,omitempty"` // Unset when the port is not published
+	Protocol    string `json:"protocol"`              // tcp, udp or sctp
+}
+
+// ContainerMount is a volume or directory mounted into a container
+type ContainerMount struct {
+	Type        string `json:"type"`           // volume, bind, tmpfs or npipe
+	Name        string `json:"name,omitempty"` // Volume name
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// ContainerNetwork is a network a container is attached to, with its addresses on it
+type ContainerNetwork struct {
+	Name        string `json:"name"`
+	IPAddress   string `json:"ip_address,omitempty"`
+	IPv6Address string `json:"ipv6_address,omitempty"`
+	Gateway     string `json:"gateway,omitempty"`
+	MacAddress  string `json:"mac_address,omitempty"`
+}
 
-	// Prepare the response slice
-	var response []ContainerResponse
-	for _, container := range containers {
-		response = append(response, ContainerResponse{
-			ID:     container.ID,
-			Image:  container.Image,
-			Status: container.Status,
+// newContainerResponse describes a container of the daemon list. Sizes are
+// only reported when withSize is set, since the daemon leaves them zero otherwise.
+func newContainerResponse(host string, container types.Container, withSize bool) ContainerResponse {
+	response := ContainerResponse{
+		Host:           host,
+		ID:             container.ID,
+		Names:          []string{},
+		Image:          container.Image,
+		Command:        container.Command,
+		Created:        time.Unix(container.Created, 0).Format(time.RFC3339),
+		State:          container.State,
+		Status:         container.Status,
+		Ports:          []ContainerPort{},
+		Labels:         container.Labels,
+		Mounts:         []ContainerMount{},
+		Networks:       []ContainerNetwork{},
+		ComposeProject: container.Labels[docker.ComposeProjectLabel],
+		ComposeService: container.Labels[docker.ComposeServiceLabel],
+	}
+	if response.Labels == nil {
+		response.Labels = map[string]string{}
+	}
+	for _, name := range container.Names {
+		response.Names = append(response.Names, strings.TrimPrefix(name, "/"))
+	}
+	for _, port := range container.Ports {
+		response.Ports = append(response.Ports, ContainerPort{
+			IP:          port.IP,
+			PrivatePort: port.PrivatePort,
+			PublicPort:  port.PublicPort,
+			Protocol:    port.Type,
+		})
+	}
+	for _, mount := range container.Mounts {
+		response.Mounts = append(response.Mounts, ContainerMount{
+			Type:        string(mount.Type),
+			Name:        mount.Name,
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
 		})
 	}
+	if container.NetworkSettings != nil {
+		for name, endpoint := range container.NetworkSettings.Networks {
+			attached := ContainerNetwork{Name: name}
+			if endpoint != nil {
+				attached.IPAddress = endpoint.IPAddress
+				attached.IPv6Address = endpoint.GlobalIPv6Address
+				attached.Gateway = endpoint.Gateway
+				attached.MacAddress = endpoint.MacAddress
+			}
+			response.Networks = append(response.Networks, attached)
+		}
+		// Networks come from a map; list them in a stable order
+		sort.Slice(response.Networks, func(i, j int) bool { return response.Networks[i].Name < response.Networks[j].Name })
+	}
+	if withSize {
+		sizeRw, sizeRootFs := container.SizeRw, container.SizeRootFs
+		response.SizeRw, response.SizeRootFs = &sizeRw, &sizeRootFs
+	}
+	return response
+}
 
-	// Set the content type to application/json
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
+// ContainerPage is a page of the container list
+type ContainerPage struct {
+	Total      int                 `json:"total"` // Matching containers in all pages
+	NextCursor string              `json:"next_cursor,omitempty"`
+	Items      []ContainerResponse `json:"items"`
+}
 
-	// Marshal the response to JSON
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+// hostContainer is a container as listed by the daemon of host
+type hostContainer struct {
+	host string
+	types.Container
+}
+
+func containerSortKey(c hostContainer, field string) sortKey {
+	key := sortKey{ID: c.host + "/" + c.ID}
+	switch field {
+	case "created":
+		key.Number = c.Created
+	case "size":
+		key.Number = c.SizeRw
+	case "name":
+		if len(c.Names) > 0 {
+			key.Text = strings.TrimPrefix(c.Names[0], "/")
+		}
 	}
+	return key
+}
+
+// ListContainersHandler lists the running containers, or every container
+// when the all query parameter is true. The status, label, name and ancestor
+// query parameters filter the list on the daemon, and size=true adds the
+// disk usage of each container.
+func (h *Handlers) ListContainersHandler(w http.ResponseWriter, r *http.Request) {
+	h.listContainers(w, r, r.URL.Query().Get("all") == "true")
+}
+
+// ListAllContainersHandler lists every container, whatever its state
+func (h *Handlers) ListAllContainersHandler(w http.ResponseWriter, r *http.Request) {
+	h.listContainers(w, r, true)
 }
 
-func ListAllContainersHandler(w http.ResponseWriter, r *http.Request) {
-	containers, err := docker.ListAllContainers()
+func (h *Handlers) listContainers(w http.ResponseWriter, r *http.Request, all bool) {
+	list, err := parseListQuery(r, "created", "size", "name")
 	if err != nil {
-		http.Error(w, "Failed to list containers", http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	// Prepare the response slice
-	var response []ContainerResponse
-	for _, container := range containers {
-		response = append(response, ContainerResponse{
-			ID:     container.ID,
-			Image:  container.Image,
-			Status: container.Status,
-		})
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
 	}
 
-	// Set the content type to application/json
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
+	// Collect the containers of every host, labelling each row with its host
+	filter := listFilters(r.URL.Query(), containerFilters)
+	// The daemon only computes sizes on request, since it is slow
+	withSize := r.URL.Query().Get("size") == "true" || list.field == "size"
+	principal := principalFor(r)
+	var rows []hostContainer
+	for _, target := range targets {
+		listHost := target.docker.ListContainers
+		if all {
+			listHost = target.docker.ListAllContainers
+		}
+		containers, err := listHost(r.Context(), filter, withSize)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to list containers on host "+target.name, err)
+			return
+		}
+
+		for _, container := range containers {
+			// Callers scoped by label only see their own containers
+			if !principal.InScope(container.Labels) {
+				continue
+			}
+			rows = append(rows, hostContainer{host: target.name, Container: container})
+		}
+	}
 
-	// Marshal the response to JSON
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	page, total, next := paginate(rows, list, containerSortKey)
+	response := []ContainerResponse{}
+	for _, container := range page {
+		response = append(response, newContainerResponse(container.host, container.Container, withSize))
 	}
+	writeList(w, r, response, ContainerPage{Total: total, NextCursor: next, Items: response})
 }
 
 type RequestBody struct {
 	ID string `json:"id"` // JSON field to hold the container ID
 }
 
+// MessageResponse reports the outcome of a single change
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// BulkRemoveResponse reports a removal of every container or image, listing
+// the resources protection kept
+type BulkRemoveResponse struct {
+	Message string                   `json:"message"`
+	Details []string                 `json:"details"`
+	Skipped []docker.SkippedResource `json:"skipped"`
+}
+
 // StartContainerHandler handles the HTTP request to start a container
-func StartContainerHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) StartContainerHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var requestBody RequestBody
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
-    
+
 	// Call the StartContainer function with the provided container ID
-	message, err := docker.StartContainer(requestBody.ID)
+	record := h.newContainerHistoryRecord(r, dockerService, "start", requestBody.ID)
+	result, err := dockerService.StartContainer(r.Context(), requestBody.ID)
+	h.saveHistory(record, result.Message, err)
 	if err != nil {
-		http.Error(w, "Failed to start container: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to start container", err)
 		return
 	}
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	// Respond with the message
-	w.Write([]byte(message))
+	// Respond with the structured result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
-func StopContainerHandler(w http.ResponseWriter, r *http.Request) {
-	var requestBody RequestBody
+func (h *Handlers) StopContainerHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var requestBody ContainerActionRequest
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the StopContainer function with the provided container ID
-	message, err := docker.StopContainer(requestBody.ID)
+	record := h.newContainerHistoryRecord(r, dockerService, "stop", requestBody.ID)
+	result, err := dockerService.StopContainer(r.Context(), requestBody.ID, requestBody.timeout())
+	h.saveHistory(record, result.Message, err)
 	if err != nil {
-		http.Error(w, "Failed to stop container: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to stop container", err)
 		return
 	}
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	// Respond with the message
-	w.Write([]byte(message))
+	// Respond with the structured result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
-func RemoveContainerHandler(w http.ResponseWriter, r *http.Request) {
-	var requestBody RequestBody
+func (h *Handlers) RemoveContainerHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var requestBody RemoveContainerRequest
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	protection, err := h.protectionFor(requestBody.ProtectionOverride)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
 	// Call the RemoveContainer function with the provided container ID
-	message, err := docker.RemoveContainer(requestBody.ID)
+	record := h.newContainerHistoryRecord(r, dockerService, "remove", requestBody.ID)
+	message, err := dockerService.RemoveContainer(r.Context(), requestBody.ID, protection)
+	h.saveHistory(record, overrideNote(message, requestBody.ProtectionOverride), err)
 	if err != nil {
-		http.Error(w, "Failed to remove container: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to remove container", err)
 		return
 	}
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	// Respond with the message
-	w.Write([]byte(message))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MessageResponse{Message: message})
+}
+
+// RemoveContainerRequest names the container to remove
+type RemoveContainerRequest struct {
+	ID string `json:"id"`
+	ProtectionOverride
+}
+
+// CreateContainerRequest is a container spec and the credentials for pulling
+// its image if it is missing.
+type CreateContainerRequest struct {
+	docker.ContainerSpec
+	RegistryCredentials
+}
+
+type CreateContainerResponse struct {
+	ID       string            `json:"id"`
+	Warnings []string          `json:"warnings"`
+	Code     docker.ResultCode `json:"code,omitempty"` // Outcome of starting the container, for /containers/run
+	Message  string            `json:"message,omitempty"`
+}
+
+// CreateContainerHandler creates a container, pulling its image if it is missing
+func (h *Handlers) CreateContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.createContainer(w, r, false)
+}
+
+// RunContainerHandler creates a container and starts it
+func (h *Handlers) RunContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.createContainer(w, r, true)
+}
+
+func (h *Handlers) createContainer(w http.ResponseWriter, r *http.Request, start bool) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var req CreateContainerRequest
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if req.Image == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Image is required")
+		return
+	}
+	spec := req.ContainerSpec
+	if spec.RegistryAuth, err = h.registryAuth(req.RegistryCredentials); err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	record := h.newHistoryRecord(r, "create")
+	record.Image = spec.Image
+	if spec.Name != "" {
+		record.ContainerNames = []string{spec.Name}
+	}
+
+	// Create, and optionally start, the container
+	var result docker.CreateResult
+	var started docker.ActionResult
+	message := "Container created successfully"
+	if start {
+		record.Action = "run"
+		result, started, err = dockerService.RunContainer(r.Context(), spec)
+		message = started.Message
+	} else {
+		result, err = dockerService.CreateContainer(r.Context(), spec)
+	}
+	record.ContainerID = result.ID
+	h.saveHistory(record, message, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to create container", err)
+		return
+	}
+
+	response := CreateContainerResponse{
+		ID:       result.ID,
+		Warnings: result.Warnings,
+		Code:     started.Code,
+		Message:  message,
+	}
+
+	// Set the response content type to JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
 }
 
 type LogResponse struct {
 	ID   string `json:"id"`
 	Logs string `json:"logs"`
 }
-func RemoveAllContainersHandler(w http.ResponseWriter, r *http.Request) {
-    // Call the RemoveAllContainers function
-    results, err := docker.RemoveAllContainers()
-    if err != nil {
-        http.Error(w, "Failed to remove containers: "+err.Error(), http.StatusInternalServerError)
-        return
-    }
-    
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-    // Set the response content type to JSON
-    w.Header().Set("Content-Type", "application/json")
-
-    // Return the result messages in JSON format
-    json.NewEncoder(w).Encode(map[string]interface{}{
-        "message": "Containers Deleted",
-        "details": results,
-    })
+
+func (h *Handlers) RemoveAllContainersHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Call the RemoveAllContainers function
+	record := h.newHistoryRecord(r, "prune")
+	results, skipped, err := dockerService.RemoveAllContainers(r.Context(), h.protection)
+	h.saveHistory(record, "Containers: "+strings.Join(results, "; "), err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to remove containers", err)
+		return
+	}
+
+	// Set the response content type to JSON
+	w.Header().Set("Content-Type", "application/json")
+
+	// Return the result messages in JSON format
+	json.NewEncoder(w).Encode(BulkRemoveResponse{
+		Message: "Containers Deleted",
+		Details: results,
+		Skipped: skipped,
+	})
 }
+
 // GetContainerLogsHandler handles the HTTP request to get logs for a container
-func GetContainerLogsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetContainerLogsHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var requestBody RequestBody
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the GetContainerLogs function with the provided container ID
-	logs, err := docker.GetContainerLogs(requestBody.ID)
+	logs, err := dockerService.GetContainerLogs(r.Context(), requestBody.ID)
 	if err != nil {
-		http.Error(w, "Failed to retrieve logs: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve logs", err)
 		return
 	}
 
@@ -199,16 +473,63 @@ func GetContainerLogsHandler(w http.ResponseWriter, r *http.Request) {
 		ID:   requestBody.ID,
 		Logs: logs,
 	}
-    
+
 	// Set the response content type to JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	json.NewEncoder(w).Encode(logResponse)
 }
 
+// StreamContainerLogsHandler streams a container's logs as Server-Sent Events.
+// stdout and stderr lines are sent as separate "stdout" and "stderr" events.
+// Query parameters: id, since, until, tail, timestamps and follow (default true).
+func (h *Handlers) StreamContainerLogsHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	containerID := pathParam(r, "id")
+	if containerID == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	options := docker.LogStreamOptions{
+		Since:      query.Get("since"),
+		Until:      query.Get("until"),
+		Tail:       query.Get("tail"),
+		Timestamps: query.Get("timestamps") == "true",
+		Follow:     query.Get("follow") != "false",
+	}
+
+	// Report a missing container as a plain error before the event stream starts
+	if _, err := dockerService.InspectContainer(r.Context(), containerID); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve logs", err)
+		return
+	}
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	stdout := &sseLineWriter{stream: stream, event: "stdout"}
+	stderr := &sseLineWriter{stream: stream, event: "stderr"}
+
+	// The request context is cancelled when the client disconnects, which ends the stream
+	err = dockerService.StreamContainerLogs(r.Context(), containerID, options, stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil {
+		stream.Send("error", err.Error())
+		return
+	}
+	stream.Send("end", "")
+}
+
 type StatsResponse struct {
 	ID     string  `json:"id"`
 	CPU    float64 `json:"cpu_usage_percent"`
@@ -216,19 +537,25 @@ type StatsResponse struct {
 }
 
 // GetContainerStatsHandler handles the HTTP request to get stats for a container
-func GetContainerStatsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetContainerStatsHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var requestBody RequestBody
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the GetContainerStats function with the provided container ID
-	stats, err := docker.GetContainerStats(requestBody.ID)
+	stats, err := dockerService.GetContainerStats(r.Context(), requestBody.ID)
 	if err != nil {
-		http.Error(w, "Failed to retrieve stats: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve stats", err)
 		return
 	}
 
@@ -241,31 +568,70 @@ func GetContainerStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Set the response content type to JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	json.NewEncoder(w).Encode(statsResponse)
 }
 
+// StreamContainerStatsHandler pushes a "stats" Server-Sent Event every interval
+// seconds (default 5) holding one sample per container. Containers are selected
+// with repeated id parameters; without any, every running container is sampled,
+// including those started later.
+func (h *Handlers) StreamContainerStatsHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	interval := 5 * time.Second
+	if value := query.Get("interval"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			writeErrorMessage(w, http.StatusBadRequest, "Interval must be a positive number of seconds")
+			return
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// The request context is cancelled when the client disconnects, which ends the stream
+	err = dockerService.StreamContainerStats(r.Context(), query["id"], interval, func(samples []docker.StatsSample) error {
+		return stream.SendJSON("stats", samples)
+	})
+	if err != nil {
+		stream.Send("error", err.Error())
+	}
+}
+
 type InspectResponse struct {
 	ContainerInfo types.ContainerJSON `json:"container_info"` // Use types.ContainerJSON directly
 }
 
 // InspectContainerHandler handles the HTTP request to inspect a container
-func InspectContainerHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) InspectContainerHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var requestBody RequestBody
 
-	// Decode the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the InspectContainer function with the provided container ID
-	containerInfo, err := docker.InspectContainer(requestBody.ID)
+	containerInfo, err := dockerService.InspectContainer(r.Context(), requestBody.ID)
 	if err != nil {
-		http.Error(w, "Failed to inspect container: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to inspect container", err)
 		return
 	}
 
@@ -276,10 +642,5 @@ func InspectContainerHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Set the response content type to JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	json.NewEncoder(w).Encode(inspectResponse)
 }
-
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"Docker_Management/pkg/docker"
+)
+
+// writeTarHeaders prepares a tarball download named filename.
+func writeTarHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+}
+
+// SaveImagesHandler streams the images given by repeated image query
+// parameters as a docker-save tarball
+func (h *Handlers) SaveImagesHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	images := r.URL.Query()["image"]
+	if len(images) == 0 {
+		writeErrorMessage(w, http.StatusBadRequest, "At least one image is required")
+		return
+	}
+
+	// Report missing images before the download starts
+	if err := dockerService.CheckImagesExist(r.Context(), images); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to save images", err)
+		return
+	}
+
+	writeTarHeaders(w, "images.tar")
+	if err := dockerService.SaveImages(r.Context(), images, w); err != nil {
+		// The response has started, so the client sees a truncated tarball
+		log.Printf("Failed to save images %s: %v", strings.Join(images, ", "), err)
+	}
+}
+
+// LoadImagesResponse lists the images loaded from a tarball
+type LoadImagesResponse struct {
+	Message string   `json:"message"`
+	Loaded  []string `json:"loaded"`
+}
+
+// LoadImagesHandler loads images from a docker-save tarball in the request body
+func (h *Handlers) LoadImagesHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	record := h.newHistoryRecord(r, "load")
+	loaded, err := dockerService.LoadImages(r.Context(), r.Body)
+	record.Image = strings.Join(loaded, ", ")
+	h.saveHistory(record, "Loaded "+record.Image, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load images", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoadImagesResponse{
+		Message: "Images loaded",
+		Loaded:  loaded,
+	})
+}
+
+// ExportContainerHandler streams the filesystem of the container given by the
+// id query parameter as a tar archive
+func (h *Handlers) ExportContainerHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	containerID := pathParam(r, "id")
+	if containerID == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	// Report a missing container before the download starts
+	if _, err := dockerService.InspectContainer(r.Context(), containerID); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to export container", err)
+		return
+	}
+
+	writeTarHeaders(w, containerID+".tar")
+	if err := dockerService.ExportContainer(r.Context(), containerID, w); err != nil {
+		// The response has started, so the client sees a truncated tarball
+		log.Printf("Failed to export container %s: %v", containerID, err)
+	}
+}
+
+// ImportImageResponse gives the ID of an imported image
+type ImportImageResponse struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+// ImportImageHandler creates an image from a filesystem tarball in the request
+// body. Query parameters: reference (repository[:tag]), message, and change
+// (a Dockerfile instruction, repeatable).
+func (h *Handlers) ImportImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	options := docker.ImportOptions{
+		Reference: query.Get("reference"),
+		Message:   query.Get("message"),
+		Changes:   query["change"],
+	}
+
+	record := h.newHistoryRecord(r, "import")
+	record.Image = options.Reference
+	imageID, err := dockerService.ImportImage(r.Context(), r.Body, options)
+	h.saveHistory(record, "Imported image "+imageID, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to import image", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportImageResponse{
+		Message: "Image imported",
+		ID:      imageID,
+	})
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"Docker_Management/pkg/models"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAuditError is how much of an error response is kept in the audit log.
+const maxAuditError = 512
+
+// redactedParams are request fields whose values never reach the audit log.
+var redactedParams = map[string]bool{
+	"password":       true,
+	"token":          true,
+	"access_token":   true,
+	"identity_token": true,
+	"auth":           true,
+	"source_auth":    true,
+	"target_auth":    true,
+}
+
+// AuditResponse is one page of the audit log.
+type AuditResponse struct {
+	Total   int64               `json:"total"`
+	Offset  int64               `json:"offset"`
+	Limit   int64               `json:"limit"`
+	Entries []models.AuditEntry `json:"entries"`
+}
+
+// auditRecorder captures the status and error text of a response.
+type auditRecorder struct {
+	http.ResponseWriter
+	status  int
+	errText strings.Builder
+}
+
+func (a *auditRecorder) WriteHeader(status int) {
+	if a.status == 0 {
+		a.status = status
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+func (a *auditRecorder) Write(p []byte) (int, error) {
+	if a.status == 0 {
+		a.status = http.StatusOK
+	}
+	if a.status >= http.StatusBadRequest && a.errText.Len() < maxAuditError {
+		remaining := maxAuditError - a.errText.Len()
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		a.errText.Write(p[:remaining])
+	}
+	return a.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming responses working through the recorder.
+func (a *auditRecorder) Flush() {
+	if flusher, ok := a.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// auditMiddleware writes an audit entry for every POST and DELETE request:
+// who made it, the route and target resources, its parameters, the result
+// and how long it took.
+func (h *Handlers) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := models.AuditEntry{
+			RequestID:  requestIDFrom(r.Context()),
+			ClientAddr: clientAddr(r),
+			Principal:  clientAddr(r),
+			Method:     r.Method,
+			Route:      r.URL.Path,
+			Host:       h.hostName(r),
+			Params:     auditParams(r),
+		}
+		operation := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				entry.Route = template
+			}
+			operation = strings.TrimPrefix(route.GetName(), legacyRoutePrefix)
+		}
+		if resourceType, ids, ok := requestTarget(r); ok {
+			entry.TargetType = string(resourceType)
+			entry.Targets = ids
+		}
+		if h.auth.Enabled() {
+			// The handler authenticates again; this only names the caller
+			entry.Principal = ""
+			if principal, err := h.auth.Authenticate(bearerToken(r)); err == nil {
+				entry.Principal = principal.Name
+				entry.Role = string(principal.Role)
+			} else if username, ok := entry.Params["username"].(string); ok && operation == "login" {
+				entry.Principal = username
+			}
+		}
+
+		recorder := &auditRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		entry.Timestamp = start.UTC()
+		entry.DurationMS = time.Since(start).Milliseconds()
+		entry.Status = recorder.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		entry.Outcome = models.OutcomeSuccess
+		if entry.Status >= http.StatusBadRequest {
+			entry.Outcome = models.OutcomeFailure
+			entry.Error = auditError(recorder.errText.String())
+		}
+
+		// Use a fresh context so the entry is kept even if the client has gone away
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.audit.Insert(ctx, entry); err != nil {
+			log.Printf("Failed to write audit entry for %s %s: %v", entry.Method, entry.Route, err)
+		}
+	})
+}
+
+// auditError returns the message of an error response, or its raw text if
+// it is not an ErrorResponse.
+func auditError(body string) string {
+	var response ErrorResponse
+	if err := json.Unmarshal([]byte(body), &response); err == nil && response.Message != "" {
+		return response.Message
+	}
+	return strings.TrimSpace(body)
+}
+
+// auditParams collects the query parameters and JSON body of a request,
+// redacting credentials.
+func auditParams(r *http.Request) map[string]interface{} {
+	params := map[string]interface{}{}
+	for key, values := range r.URL.Query() {
+		if len(values) == 1 {
+			params[key] = values[0]
+		} else {
+			params[key] = values
+		}
+	}
+	for key, value := range peekJSONBody(r) {
+		params[key] = value
+	}
+
+	redact(params)
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// redact replaces the values of credential fields, at any depth.
+func redact(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if redactedParams[strings.ToLower(key)] {
+				value[key] = "[redacted]"
+				continue
+			}
+			redact(nested)
+		}
+	case []interface{}:
+		for _, nested := range value {
+			redact(nested)
+		}
+	}
+}
+
+// ListAuditHandler pages through the audit log, newest first. Query
+// parameters: principal, method, route, target (an ID or name, prefixes
+// match), host, outcome, since and until (RFC 3339), offset and limit.
+func (h *Handlers) ListAuditHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Principal: query.Get("principal"),
+		Method:    strings.ToUpper(query.Get("method")),
+		Route:     query.Get("route"),
+		Host:      query.Get("host"),
+		Target:    query.Get("target"),
+		Outcome:   query.Get("outcome"),
+	}
+
+	page, err := parseLogPage(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	filter.Since, filter.Until, filter.Skip, filter.Limit = page.since, page.until, page.skip, page.limit
+
+	entries, total, err := h.audit.Find(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve audit log", err)
+		return
+	}
+
+	response := AuditResponse{
+		Total:   total,
+		Offset:  filter.Skip,
+		Limit:   filter.Limit,
+		Entries: entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/docker"
+
+	"github.com/gorilla/mux"
+)
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token     string         `json:"token"` // Sent back as "Authorization: Bearer <token>"
+	ExpiresAt time.Time      `json:"expires_at"`
+	Principal auth.Principal `json:"principal"`
+}
+
+// LoginHandler exchanges a username and password for a login token
+func (h *Handlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	token, expiresAt, principal, err := h.auth.Login(req.Username, req.Password)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Failed to log in", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{Token: token, ExpiresAt: expiresAt, Principal: principal})
+}
+
+// WhoAmIHandler describes the caller, so a UI can hide what its role cannot do
+func (h *Handlers) WhoAmIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(principalFor(r))
+}
+
+// principalFor returns the caller of a request that passed require.
+func principalFor(r *http.Request) auth.Principal {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal
+	}
+	return auth.Anonymous
+}
+
+// bearerToken reads the token from the Authorization header, or from the
+// access_token query parameter for EventSource and WebSocket clients, which
+// cannot set headers.
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// require wraps a handler so that only callers with at least role reach it.
+// Callers scoped by label may only act on resources carrying those labels.
+func (h *Handlers) require(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.Anonymous
+		if h.auth.Enabled() {
+			var err error
+			principal, err = h.auth.Authenticate(bearerToken(r))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="docker-management"`)
+				writeError(w, http.StatusUnauthorized, "Authentication required", err)
+				return
+			}
+		}
+
+		if !principal.Role.Allows(role) {
+			writeErrorMessage(w, http.StatusForbidden, "Forbidden: the "+string(principal.Role)+" role cannot perform this action")
+			return
+		}
+		if principal.Scoped() {
+			if err := h.checkScope(r, principal); err != nil {
+				writeError(w, http.StatusForbidden, "Forbidden", err)
+				return
+			}
+		}
+
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// scopeFiltered are the operations without a target that limit what they
+// return or reach to the caller's scope themselves.
+var scopeFiltered = map[string]bool{
+	"whoAmI":             true,
+	"listHosts":          true,
+	"listHistory":        true,
+	"streamEvents":       true,
+	"listJobs":           true,
+	"getJob":             true,
+	"streamJob":          true,
+	"cancelJob":          true,
+	"listContainers":     true,
+	"listAllContainers":  true,
+	"listImages":         true,
+	"listDanglingImages": true,
+	"listVolumes":        true,
+	"listNetworks":       true,
+	"listProjects":       true,
+	"inspectProject":     true,
+	"streamProjectLogs":  true,
+}
+
+// checkScope refuses a request from a scoped caller unless every resource it
+// names carries the caller's labels. Requests without a target are refused,
+// since they could reach any resource, unless the operation filters its
+// results by scope.
+func (h *Handlers) checkScope(r *http.Request, principal auth.Principal) error {
+	resourceType, ids, ok := requestTarget(r)
+	if !ok || len(ids) == 0 {
+		if route := mux.CurrentRoute(r); route != nil && scopeFiltered[strings.TrimPrefix(route.GetName(), legacyRoutePrefix)] {
+			return nil
+		}
+		return errors.New("this action is not available to users limited to labelled resources")
+	}
+
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		lookupType := resourceType
+		if lookupType == "" {
+			// An exec session belongs to the container it runs in
+			if id, err = dockerService.ExecContainer(r.Context(), id); err != nil {
+				return err
+			}
+			lookupType = docker.PruneContainers
+		}
+
+		resource, err := dockerService.LookupResource(r.Context(), lookupType, id)
+		if err != nil {
+			return err
+		}
+		if !principal.InScope(resource.Labels) {
+			return errors.New(id + " is outside your scope")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"Docker_Management/pkg/config"
+	"Docker_Management/pkg/docker"
+	"Docker_Management/pkg/models"
+)
+
+const (
+	adminToken = "admin-token"
+	teamToken  = "team-a-token"
+)
+
+// newScopedTestAPI serves the API with an unscoped admin token and an
+// operator token limited to resources labelled team=a.
+func newScopedTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	tokenHash := func(token string) string {
+		sum := sha256.Sum256([]byte(token))
+		return hex.EncodeToString(sum[:])
+	}
+	return newTestAPI(t, newStubService(), config.AuthConfig{Tokens: []config.APIToken{
+		{Name: "admin", TokenSHA256: tokenHash(adminToken), Role: "admin"},
+		{Name: "team-a", TokenSHA256: tokenHash(teamToken), Role: "operator", Scope: []string{"team=a"}},
+	}})
+}
+
+func TestScopedReadsAreFiltered(t *testing.T) {
+	api := newScopedTestAPI(t)
+
+	rec := api.doAs(teamToken, http.MethodGet, "/api/v1/containers?all=true", "")
+	var containers ContainerPage
+	json.NewDecoder(rec.Body).Decode(&containers)
+	if rec.Code != http.StatusOK || containers.Total != 1 || containers.Items[0].ID != "aaa" {
+		t.Errorf("containers: status %d, %+v, want only aaa", rec.Code, containers.Items)
+	}
+
+	// The stub's images, volumes and networks carry no labels
+	for _, path := range []string{"/api/v1/images", "/api/v1/images/dangling", "/api/v1/volumes", "/api/v1/networks"} {
+		rec := api.doAs(teamToken, http.MethodGet, path, "")
+		var page struct{ Total int }
+		json.NewDecoder(rec.Body).Decode(&page)
+		if rec.Code != http.StatusOK || page.Total != 0 {
+			t.Errorf("%s: status %d, %d rows, want none", path, rec.Code, page.Total)
+		}
+	}
+
+	// The admin's actions and jobs are not shown to the scoped operator
+	api.doAs(adminToken, http.MethodPost, "/api/v1/containers/bbb/start", "")
+	api.doAs(adminToken, http.MethodPost, "/api/v1/images/pull/jobs", `{"image":"nginx"}`)
+	api.doAs(teamToken, http.MethodPost, "/api/v1/containers/aaa/stop", "")
+
+	rec = api.doAs(teamToken, http.MethodGet, "/api/v1/history", "")
+	var history HistoryResponse
+	json.NewDecoder(rec.Body).Decode(&history)
+	if history.Total != 1 || history.Records[0].Actor != "team-a" {
+		t.Errorf("history: %+v, want only the stop by team-a", history.Records)
+	}
+
+	rec = api.doAs(teamToken, http.MethodGet, "/api/v1/jobs", "")
+	var jobs []docker.JobInfo
+	json.NewDecoder(rec.Body).Decode(&jobs)
+	if len(jobs) != 0 {
+		t.Errorf("jobs: %+v, want none", jobs)
+	}
+	adminJobs := api.jobs.List()
+	if rec := api.doAs(teamToken, http.MethodGet, "/api/v1/jobs/"+adminJobs[0].ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("another caller's job: status %d, want 404", rec.Code)
+	}
+}
+
+func TestScopedUntargetedReadsAreRefused(t *testing.T) {
+	api := newScopedTestAPI(t)
+
+	for _, path := range []string{"/api/v1/containers/stats/stream", "/api/v1/images/usage", "/images/usage"} {
+		if rec := api.doAs(teamToken, http.MethodGet, path, ""); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", path, rec.Code)
+		}
+		if rec := api.doAs(adminToken, http.MethodGet, path, ""); rec.Code == http.StatusForbidden {
+			t.Errorf("%s: refused to an unscoped caller", path)
+		}
+	}
+
+	if rec := api.doAs(teamToken, http.MethodGet, "/api/v1/containers/bbb/stats", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("stats of a container out of scope: status %d, want 403", rec.Code)
+	}
+	if rec := api.doAs(teamToken, http.MethodGet, "/api/v1/containers/aaa/stats", ""); rec.Code != http.StatusOK {
+		t.Errorf("stats of a container in scope: status %d, want 200", rec.Code)
+	}
+}
+
+func TestScopeChecksEveryTarget(t *testing.T) {
+	api := newScopedTestAPI(t)
+
+	// bbb is out of scope wherever the request names it
+	requests := []struct{ path, body string }{
+		{"/api/v1/containers/aaa/stop", `{"id":"bbb"}`},
+		{"/containers/stop?id=aaa", `{"id":"bbb"}`},
+		{"/containers/stop?id=bbb", `{"id":"aaa"}`},
+	}
+	for _, req := range requests {
+		if rec := api.doAs(teamToken, http.MethodPost, req.path, req.body); rec.Code != http.StatusForbidden {
+			t.Errorf("POST %s %s: status %d, want 403", req.path, req.body, rec.Code)
+		}
+		// Unscoped callers may not name two containers either
+		if rec := api.doAs(adminToken, http.MethodPost, req.path, req.body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s %s as admin: status %d, want 400", req.path, req.body, rec.Code)
+		}
+	}
+
+	if rec := api.doAs(teamToken, http.MethodPost, "/containers/stop?id=aaa", `{"id":"aaa"}`); rec.Code != http.StatusOK {
+		t.Errorf("the same container in the body and query: status %d, want 200", rec.Code)
+	}
+}
+
+func TestScopeChecksCaseVariantAndDuplicateBodyTargets(t *testing.T) {
+	api := newScopedTestAPI(t)
+
+	// The handlers decode bodies into structs, which match keys in any case
+	// and keep the last duplicate, so bbb must be checked in each of these
+	bodies := []string{
+		`{"id":"aaa","ID":"bbb"}`,
+		`{"id":"aaa","Id":"bbb"}`,
+		`{"ID":"bbb","id":"aaa"}`,
+		`{"id":"bbb","id":"aaa"}`,
+	}
+	for _, action := range []string{"start", "stop", "restart"} {
+		for _, body := range bodies {
+			path := "/containers/" + action
+			if rec := api.doAs(teamToken, http.MethodPost, path, body); rec.Code != http.StatusForbidden {
+				t.Errorf("POST %s %s: status %d, want 403", path, body, rec.Code)
+			}
+		}
+	}
+}
+
+func TestLoginAuditNamesUser(t *testing.T) {
+	api := newScopedTestAPI(t)
+
+	for _, path := range []string{"/api/v1/auth/login", "/auth/login"} {
+		if rec := api.do(http.MethodPost, path, `{"username":"mallory","password":"guess"}`); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("POST %s: status %d, want 401", path, rec.Code)
+		}
+		entries, _, err := api.audit.Find(context.Background(), models.AuditFilter{Route: path, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Principal != "mallory" {
+			t.Errorf("POST %s: audit entries %+v, want one naming mallory", path, entries)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"Docker_Management/pkg/docker"
+)
+
+// buildContextTypes are the content types accepted as an uploaded build context.
+var buildContextTypes = map[string]bool{
+	"application/x-tar":  true,
+	"application/tar":    true,
+	"application/gzip":   true,
+	"application/x-gzip": true,
+}
+
+// BuildImageHandler starts an image build in the background and returns the
+// job, whose output can be followed on /jobs/stream.
+//
+// A tar (optionally gzipped) request body is used as the build context, with
+// the options given as query parameters: dockerfile, target, tag, buildarg and
+// label (KEY=value, repeatable), nocache and pull. Otherwise the body is a JSON
+// BuildSpec naming a context_path or git_url.
+func (h *Handlers) BuildImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var spec docker.BuildSpec
+	var contextFile *os.File
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if buildContextTypes[mediaType] {
+		spec = buildSpecFromQuery(r)
+
+		// Spool the upload to disk, since the build outlives this request
+		contextFile, err = spoolBuildContext(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to read build context", err)
+			return
+		}
+	} else {
+		// Decode the JSON request body
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		if spec.ContextPath == "" && spec.GitURL == "" {
+			writeErrorMessage(w, http.StatusBadRequest, "A build context upload, context_path or git_url is required")
+			return
+		}
+		if spec.ContextPath != "" {
+			if spec.ContextPath, err = docker.ResolveBuildContext(h.buildRoot, spec.ContextPath); err != nil {
+				writeError(w, http.StatusBadRequest, "", err)
+				return
+			}
+		}
+	}
+
+	target := strings.Join(spec.Tags, ", ")
+	record := h.newHistoryRecord(r, "build")
+	record.Image = target
+
+	job := h.jobs.Start("build", record.Host, target, record.Actor, func(ctx context.Context, job *docker.Job) error {
+		var buildContext io.Reader
+		if contextFile != nil {
+			defer os.Remove(contextFile.Name())
+			defer contextFile.Close()
+			buildContext = contextFile
+		}
+
+		imageID, err := dockerService.BuildImage(ctx, buildContext, spec, job.Progress)
+		if err == nil {
+			job.SetResult(imageID)
+			if record.Image == "" {
+				record.Image = imageID
+			}
+		}
+		h.saveHistory(record, "Built image "+imageID, err)
+		return err
+	})
+
+	h.writeJob(w, http.StatusAccepted, job)
+}
+
+// buildSpecFromQuery reads the build options of an uploaded context from the query string.
+func buildSpecFromQuery(r *http.Request) docker.BuildSpec {
+	query := r.URL.Query()
+	return docker.BuildSpec{
+		Dockerfile: query.Get("dockerfile"),
+		Target:     query.Get("target"),
+		Tags:       query["tag"],
+		BuildArgs:  keyValues(query["buildarg"]),
+		Labels:     keyValues(query["label"]),
+		NoCache:    query.Get("nocache") == "true",
+		Pull:       query.Get("pull") == "true",
+	}
+}
+
+// keyValues parses KEY=value pairs. A pair without "=" maps the key to "".
+func keyValues(pairs []string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		values[key] = value
+	}
+	return values
+}
+
+// spoolBuildContext copies an uploaded build context into a temporary file
+// and rewinds it for reading.
+func spoolBuildContext(body io.Reader) (*os.File, error) {
+	file, err := os.CreateTemp("", "build-context-*.tar")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"Docker_Management/pkg/docker"
+)
+
+// Error codes sent in ErrorResponse. Container state refusals use the
+// docker.ResultCode of the refusal instead, e.g. already_running.
+const (
+	CodeInvalidInput      = "invalid_input"
+	CodeUnauthenticated   = "unauthenticated"
+	CodeForbidden         = "forbidden"
+	CodeProtected         = "protected"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeTooLarge          = "request_too_large"
+	CodeDaemonUnavailable = "daemon_unavailable"
+	CodeInternal          = "internal_error"
+)
+
+// ErrorResponse is the body of every error response.
+type ErrorResponse struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// statusCodes gives the code sent for errors of no known type.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeInvalidInput,
+	http.StatusUnauthorized:          CodeUnauthenticated,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusServiceUnavailable:    CodeDaemonUnavailable,
+}
+
+// writeError responds with the status and code that err's type maps to, or
+// with status for errors of no known type. A non-empty message is put in
+// front of the error text.
+func writeError(w http.ResponseWriter, status int, message string, err error) {
+	if err == nil {
+		writeErrorMessage(w, status, message)
+		return
+	}
+	status, response := errorResponse(status, err)
+	if message != "" {
+		response.Message = message + ": " + response.Message
+	}
+	sendError(w, status, response)
+}
+
+// writeErrorMessage responds with status and a message that is not backed by an error.
+func writeErrorMessage(w http.ResponseWriter, status int, message string) {
+	sendError(w, status, ErrorResponse{Code: codeForStatus(status), Message: message})
+}
+
+// errorResponse maps err to a status and response body.
+func errorResponse(status int, err error) (int, ErrorResponse) {
+	err = docker.Classify(err)
+	response := ErrorResponse{Message: err.Error()}
+
+	var notFound *docker.NotFoundError
+	var conflict *docker.ConflictError
+	var state *docker.StateError
+	var invalid *docker.InvalidInputError
+	var unavailable *docker.UnavailableError
+	var protected *docker.ProtectedError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &protected):
+		status, response.Code = http.StatusForbidden, CodeProtected
+		response.Details = map[string]interface{}{"type": protected.Resource.Type, "id": protected.Resource.ID, "rule": protected.Rule}
+	case errors.As(err, &notFound):
+		status, response.Code = http.StatusNotFound, CodeNotFound
+		if notFound.Kind != "" {
+			response.Details = map[string]interface{}{"kind": notFound.Kind, "id": notFound.ID}
+		}
+	case errors.As(err, &state):
+		status, response.Code = http.StatusConflict, string(state.Code)
+		response.Details = map[string]interface{}{"container_id": state.ContainerID}
+	case errors.As(err, &conflict):
+		status, response.Code = http.StatusConflict, CodeConflict
+	case errors.As(err, &invalid):
+		status, response.Code = http.StatusBadRequest, CodeInvalidInput
+	case errors.As(err, &unavailable):
+		status, response.Code = http.StatusServiceUnavailable, CodeDaemonUnavailable
+	case errors.As(err, &tooLarge):
+		status, response.Code = http.StatusRequestEntityTooLarge, CodeTooLarge
+		response.Details = map[string]interface{}{"limit": tooLarge.Limit}
+	default:
+		response.Code = codeForStatus(status)
+	}
+	return status, response
+}
+
+// codeForStatus returns the code sent with a status, internal_error by default.
+func codeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return CodeInternal
+}
+
+func sendError(w http.ResponseWriter, status int, response ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"Docker_Management/pkg/docker"
+)
+
+// eventKeepAliveInterval is how often an idle event stream is pinged.
+const eventKeepAliveInterval = 30 * time.Second
+
+// StreamEventsHandler streams daemon events as Server-Sent Events named after
+// the event type (container, image, volume or network). Filters are given as
+// repeated type, action and label (key or key=value) query parameters, and
+// host=all follows every registered host.
+func (h *Handlers) StreamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := docker.EventFilter{
+		Types:   query["type"],
+		Actions: query["action"],
+		Labels:  query["label"],
+	}
+	for _, target := range targets {
+		filter.Hosts = append(filter.Hosts, target.name)
+	}
+	// Callers scoped by label only see events of resources carrying their
+	// labels, which the daemon reports among the actor attributes
+	filter.Labels = append(filter.Labels, principalFor(r).Scope...)
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	subscription := h.events.Subscribe(filter)
+	defer h.events.Unsubscribe(subscription)
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	// The request context is cancelled when the client disconnects, which ends the stream
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if err := stream.Ping(); err != nil {
+				return
+			}
+		case event := <-subscription.Events():
+			if err := stream.SendJSON(event.Type, event); err != nil {
+				return
+			}
+		}
+	}
+}
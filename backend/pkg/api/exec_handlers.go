@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+
+	"Docker_Management/pkg/docker"
+
+	"github.com/gorilla/websocket"
+)
+
+// Exec WebSocket message types. The client sends stdin, close_stdin and
+// resize; the backend sends stdout, stderr, exit and error.
+const (
+	execMessageStdin      = "stdin"
+	execMessageCloseStdin = "close_stdin"
+	execMessageResize     = "resize"
+	execMessageStdout     = "stdout"
+	execMessageStderr     = "stderr"
+	execMessageExit       = "exit"
+	execMessageError      = "error"
+)
+
+// execUpgrader accepts WebSocket connections from the allowed CORS origins
+// and from non-browser clients.
+func (h *Handlers) execUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || h.cors.allows(origin)
+		},
+	}
+}
+
+// ExecMessage is one message on the exec WebSocket.
+type ExecMessage struct {
+	Type     string `json:"type"`
+	Data     string `json:"data,omitempty"`
+	Rows     uint   `json:"rows,omitempty"`
+	Cols     uint   `json:"cols,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // base64 when Data holds output that is not valid UTF-8
+}
+
+type CreateExecRequest struct {
+	ID string `json:"id"` // Container ID
+	docker.ExecSpec
+}
+
+type CreateExecResponse struct {
+	ExecID string `json:"exec_id"`
+}
+
+// CreateExecHandler prepares a command to run in a container. The command
+// starts when a client attaches to /containers/exec/attach.
+func (h *Handlers) CreateExecHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var requestBody CreateExecRequest
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if requestBody.ID == "" || len(requestBody.Cmd) == 0 {
+		writeErrorMessage(w, http.StatusBadRequest, "Container ID and command are required")
+		return
+	}
+
+	record := h.newContainerHistoryRecord(r, dockerService, "exec", requestBody.ID)
+	execID, err := dockerService.CreateExec(r.Context(), requestBody.ID, requestBody.ExecSpec)
+	h.saveHistory(record, "Exec "+execID+" created", err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to create exec", err)
+		return
+	}
+
+	// Set the response content type to JSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateExecResponse{ExecID: execID})
+}
+
+// AttachExecHandler starts an exec instance and connects it to a WebSocket.
+// Query parameters: exec (the exec ID) and tty, which must match how the exec
+// was created. The socket is closed after the exit message.
+func (h *Handlers) AttachExecHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	execID := pathParam(r, "exec")
+	if execID == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Exec ID is required")
+		return
+	}
+	tty := query.Get("tty") == "true"
+
+	conn, err := h.execUpgrader().Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		return
+	}
+	defer conn.Close()
+	socket := &execSocket{conn: conn}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Feed client messages to the command until the socket closes
+	stdinReader, stdinWriter := io.Pipe()
+	go func() {
+		defer cancel()
+		defer stdinWriter.Close()
+		for {
+			var message ExecMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			switch message.Type {
+			case execMessageStdin:
+				if _, err := stdinWriter.Write([]byte(message.Data)); err != nil {
+					return
+				}
+			case execMessageCloseStdin:
+				stdinWriter.Close()
+			case execMessageResize:
+				if err := dockerService.ResizeExec(ctx, execID, message.Rows, message.Cols); err != nil {
+					socket.Send(ExecMessage{Type: execMessageError, Data: "Failed to resize terminal: " + err.Error()})
+				}
+			}
+		}
+	}()
+
+	stdout := &execStreamWriter{socket: socket, stream: execMessageStdout}
+	stderr := &execStreamWriter{socket: socket, stream: execMessageStderr}
+	exitCode, err := dockerService.RunExec(ctx, execID, tty, stdinReader, stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil {
+		if ctx.Err() == nil {
+			socket.Send(ExecMessage{Type: execMessageError, Data: err.Error()})
+		}
+		return
+	}
+
+	if exitCode == docker.ExecExitUnknown {
+		// The output ended but the command did not; report no code rather than a false 0
+		socket.Send(ExecMessage{Type: execMessageExit, Data: "Exit code unknown: the command was still running when its output ended"})
+	} else {
+		socket.Send(ExecMessage{Type: execMessageExit, ExitCode: &exitCode})
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// execSocket serialises writes to the exec WebSocket, which allows only one writer at a time.
+type execSocket struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (s *execSocket) Send(message ExecMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(message)
+}
+
+// execStreamWriter is an io.Writer that forwards output as stdout or stderr
+// messages. A character split across writes is held back until it is whole,
+// and output that is still not valid UTF-8 is sent base64-encoded, since a
+// JSON text frame would replace it with U+FFFD.
+type execStreamWriter struct {
+	socket  *execSocket
+	stream  string
+	pending []byte // Start of a character the next write completes
+}
+
+func (ew *execStreamWriter) Write(p []byte) (int, error) {
+	data := append(ew.pending, p...)
+	ew.pending = nil
+
+	if cut := incompleteRuneStart(data); cut < len(data) {
+		ew.pending = append([]byte(nil), data[cut:]...)
+		data = data[:cut]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	if err := ew.send(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush sends the output held back at the end of the stream.
+func (ew *execStreamWriter) Flush() error {
+	if len(ew.pending) == 0 {
+		return nil
+	}
+	data := ew.pending
+	ew.pending = nil
+	return ew.send(data)
+}
+
+func (ew *execStreamWriter) send(data []byte) error {
+	if utf8.Valid(data) {
+		return ew.socket.Send(ExecMessage{Type: ew.stream, Data: string(data)})
+	}
+	return ew.socket.Send(ExecMessage{Type: ew.stream, Data: base64.StdEncoding.EncodeToString(data), Encoding: "base64"})
+}
+
+// incompleteRuneStart returns where a UTF-8 character cut off at the end of
+// data begins, or len(data) if the last character is whole.
+func incompleteRuneStart(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Docker_Management/pkg/config"
+	"Docker_Management/pkg/docker"
+
+	"github.com/gorilla/websocket"
+)
+
+// attachExec runs the stub's exec over a WebSocket and returns its stdout,
+// decoded, and the exit message.
+func attachExec(t *testing.T, stub *stubService) (string, ExecMessage) {
+	t.Helper()
+	server := httptest.NewServer(newTestAPI(t, stub, config.AuthConfig{}).router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/exec/exec1/attach", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var stdout strings.Builder
+	for {
+		var message ExecMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("no exit message: %v", err)
+		}
+		switch message.Type {
+		case execMessageStdout:
+			data := message.Data
+			if message.Encoding == "base64" {
+				decoded, err := base64.StdEncoding.DecodeString(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = string(decoded)
+			} else if strings.ContainsRune(data, '�') {
+				t.Errorf("text frame %q holds a replacement character", data)
+			}
+			stdout.WriteString(data)
+		case execMessageExit:
+			return stdout.String(), message
+		}
+	}
+}
+
+func TestAttachExecReportsOutputAndExitCode(t *testing.T) {
+	stub := newStubService()
+	// é and 世 are split across writes, and \xff\x00 is not text at all
+	stub.execOutput = []string{"h\xc3", "\xa9llo \xe4\xb8", "\x96\n", "\xff\x00", "bye\xe4"}
+	stub.execExitCode = 3
+
+	stdout, exit := attachExec(t, stub)
+	if want := "héllo 世\n\xff\x00bye\xe4"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if exit.ExitCode == nil || *exit.ExitCode != 3 {
+		t.Errorf("exit message = %+v, want exit code 3", exit)
+	}
+}
+
+func TestAttachExecReportsUnknownExitCode(t *testing.T) {
+	stub := newStubService()
+	stub.execExitCode = docker.ExecExitUnknown
+
+	stdout, exit := attachExec(t, stub)
+	if stdout != "ok\n" {
+		t.Errorf("stdout = %q, want ok", stdout)
+	}
+	if exit.ExitCode != nil || exit.Data == "" {
+		t.Errorf("exit message = %+v, want no exit code and an explanation", exit)
+	}
+}
@@ -0,0 +1,601 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/config"
+	"Docker_Management/pkg/db"
+	"Docker_Management/pkg/docker"
+	"Docker_Management/pkg/models"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// stubService is an in-memory docker.Service: two containers, an image, a
+// volume, a network and a Compose project, with every action succeeding.
+type stubService struct {
+	docker.Service
+
+	mu         sync.Mutex
+	containers []types.Container
+	started    []string
+	created    []docker.ContainerSpec
+
+	execOutput   []string // Chunks RunExec writes to stdout; "ok\n" when nil
+	execExitCode int
+}
+
+func newStubService() *stubService {
+	return &stubService{containers: []types.Container{
+		{ID: "aaa", Names: []string{"/web"}, Image: "nginx", State: "running", Created: 200, Labels: map[string]string{
+			docker.ComposeProjectLabel: "shop", docker.ComposeServiceLabel: "web", "team": "a",
+		}},
+		{ID: "bbb", Names: []string{"/db"}, Image: "postgres", State: "exited", Created: 100, Labels: map[string]string{"team": "b"}},
+	}}
+}
+
+func (s *stubService) container(id string) (types.Container, error) {
+	for _, c := range s.containers {
+		if c.ID == id || c.Names[0] == "/"+id {
+			return c, nil
+		}
+	}
+	return types.Container{}, &docker.NotFoundError{Kind: "container", ID: id}
+}
+
+func (s *stubService) DaemonHost() string { return "unix:///stub.sock" }
+
+func (s *stubService) Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	return make(chan events.Message), make(chan error)
+}
+
+func (s *stubService) Close() error { return nil }
+
+func (s *stubService) LookupResource(ctx context.Context, resourceType docker.PruneType, id string) (docker.Resource, error) {
+	switch resourceType {
+	case docker.PruneContainers:
+		c, err := s.container(id)
+		if err != nil {
+			return docker.Resource{}, err
+		}
+		return docker.Resource{Type: resourceType, ID: c.ID, Names: c.Names, Labels: c.Labels}, nil
+	case docker.PruneImages:
+		return docker.Resource{Type: resourceType, ID: "sha256:img", Names: []string{"nginx:latest"}}, nil
+	}
+	return docker.Resource{Type: resourceType, ID: id, Names: []string{id}}, nil
+}
+
+func (s *stubService) Prune(ctx context.Context, opts docker.PruneOptions, protection *docker.ProtectionPolicy) (docker.PruneReport, error) {
+	return docker.PruneReport{DryRun: opts.DryRun, Results: []docker.PruneResult{{Type: docker.PruneContainers, Items: []docker.PruneItem{{ID: "bbb"}}}}}, nil
+}
+
+func (s *stubService) ListContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error) {
+	var running []types.Container
+	for _, c := range s.containers {
+		if c.State == "running" {
+			running = append(running, c)
+		}
+	}
+	return running, nil
+}
+
+func (s *stubService) ListAllContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error) {
+	return s.containers, nil
+}
+
+func (s *stubService) CreateContainer(ctx context.Context, spec docker.ContainerSpec) (docker.CreateResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, spec)
+	return docker.CreateResult{ID: "ccc", Warnings: []string{}}, nil
+}
+
+func (s *stubService) RunContainer(ctx context.Context, spec docker.ContainerSpec) (docker.CreateResult, docker.ActionResult, error) {
+	return docker.CreateResult{ID: "ccc", Warnings: []string{}}, docker.ActionResult{Code: docker.ResultStarted, Message: "Container started"}, nil
+}
+
+func (s *stubService) InspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	c, err := s.container(containerID)
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: c.ID, Name: c.Names[0], State: &types.ContainerState{Status: c.State}},
+		Config:            &container.Config{Image: c.Image, Labels: c.Labels},
+		NetworkSettings:   &types.NetworkSettings{Networks: map[string]*network.EndpointSettings{}},
+	}, nil
+}
+
+func (s *stubService) action(containerID string, code docker.ResultCode) (docker.ActionResult, error) {
+	if _, err := s.container(containerID); err != nil {
+		return docker.ActionResult{}, err
+	}
+	return docker.ActionResult{Code: code, Message: "Container " + string(code)}, nil
+}
+
+func (s *stubService) StartContainer(ctx context.Context, containerID string) (docker.ActionResult, error) {
+	result, err := s.action(containerID, docker.ResultStarted)
+	if err == nil {
+		s.mu.Lock()
+		s.started = append(s.started, containerID)
+		s.mu.Unlock()
+	}
+	return result, err
+}
+
+func (s *stubService) StopContainer(ctx context.Context, containerID string, timeout *time.Duration) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultStopped)
+}
+
+func (s *stubService) RestartContainer(ctx context.Context, containerID string, timeout *time.Duration) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultRestarted)
+}
+
+func (s *stubService) PauseContainer(ctx context.Context, containerID string) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultPaused)
+}
+
+func (s *stubService) UnpauseContainer(ctx context.Context, containerID string) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultUnpaused)
+}
+
+func (s *stubService) KillContainer(ctx context.Context, containerID string, signal string) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultKilled)
+}
+
+func (s *stubService) RenameContainer(ctx context.Context, containerID string, newName string) (docker.ActionResult, error) {
+	return s.action(containerID, docker.ResultRenamed)
+}
+
+func (s *stubService) WaitContainer(ctx context.Context, containerID string, condition string) (docker.ActionResult, error) {
+	exitCode := 0
+	result, err := s.action(containerID, docker.ResultExited)
+	result.ExitCode = &exitCode
+	return result, err
+}
+
+func (s *stubService) RemoveContainer(ctx context.Context, containerID string, protection *docker.ProtectionPolicy) (string, error) {
+	c, err := s.container(containerID)
+	if err != nil {
+		return "", err
+	}
+	if err := protection.Check(docker.Resource{Type: docker.PruneContainers, ID: c.ID, Names: []string{strings.TrimPrefix(c.Names[0], "/")}, Labels: c.Labels}); err != nil {
+		return "", err
+	}
+	return "Container " + containerID + " removed", nil
+}
+
+func (s *stubService) RemoveAllContainers(ctx context.Context, protection *docker.ProtectionPolicy) ([]string, []docker.SkippedResource, error) {
+	return []string{"Container bbb removed"}, []docker.SkippedResource{{ID: "aaa", Name: "web", Rule: "keep"}}, nil
+}
+
+func (s *stubService) GetContainerLogs(ctx context.Context, containerID string) (string, error) {
+	return "hello\n", nil
+}
+
+func (s *stubService) StreamContainerLogs(ctx context.Context, containerID string, opts docker.LogStreamOptions, stdout, stderr io.Writer) error {
+	_, err := io.WriteString(stdout, "hello\n")
+	return err
+}
+
+func (s *stubService) GetContainerStats(ctx context.Context, containerID string) (docker.ContainerStats, error) {
+	return docker.ContainerStats{ID: containerID, CPU: 1.5, Memory: 20}, nil
+}
+
+func (s *stubService) StreamContainerStats(ctx context.Context, containerIDs []string, interval time.Duration, emit func([]docker.StatsSample) error) error {
+	return emit([]docker.StatsSample{{ID: "aaa", Name: "web", Read: time.Now()}})
+}
+
+func (s *stubService) ExportContainer(ctx context.Context, containerID string, w io.Writer) error {
+	_, err := io.WriteString(w, "tar")
+	return err
+}
+
+func (s *stubService) CreateExec(ctx context.Context, containerID string, spec docker.ExecSpec) (string, error) {
+	return "exec1", nil
+}
+
+func (s *stubService) ExecContainer(ctx context.Context, execID string) (string, error) {
+	return "aaa", nil
+}
+
+func (s *stubService) RunExec(ctx context.Context, execID string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	output := s.execOutput
+	if output == nil {
+		output = []string{"ok\n"}
+	}
+	for _, chunk := range output {
+		if _, err := io.WriteString(stdout, chunk); err != nil {
+			return 0, err
+		}
+	}
+	return s.execExitCode, nil
+}
+
+func (s *stubService) ResizeExec(ctx context.Context, execID string, rows, cols uint) error {
+	return nil
+}
+
+func (s *stubService) ListImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{{ID: "sha256:img", RepoTags: []string{"nginx:latest"}, Created: 100, Size: 1000}}, nil
+}
+
+func (s *stubService) ListDanglingImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{{ID: "sha256:old", Created: 50, Size: 10}}, nil
+}
+
+func (s *stubService) InspectImage(ctx context.Context, imageID string) (types.ImageInspect, error) {
+	return types.ImageInspect{ID: "sha256:img", RepoTags: []string{"nginx:latest"}, Config: &container.Config{}}, nil
+}
+
+func (s *stubService) ImageHistory(ctx context.Context, imageID string) ([]docker.ImageLayer, error) {
+	return []docker.ImageLayer{{ID: "sha256:img", CreatedBy: "CMD nginx", Created: time.Unix(100, 0), Size: 1000}}, nil
+}
+
+func (s *stubService) ImageUsage(ctx context.Context) (docker.ImageUsageReport, error) {
+	return docker.ImageUsageReport{LayersSize: 1000, TotalSize: 1000, Images: []docker.ImageUsage{{ID: "sha256:img", Tags: []string{"nginx:latest"}, Size: 1000, UniqueSize: 1000}}, BaseImages: []docker.BaseImageUsage{}}, nil
+}
+
+func (s *stubService) CheckImagesExist(ctx context.Context, images []string) error {
+	return nil
+}
+
+func (s *stubService) PullImage(ctx context.Context, image string, opts docker.PullOptions) (string, error) {
+	if opts.Progress != nil {
+		opts.Progress(jsonmessage.JSONMessage{Status: "Downloading", ID: "layer"})
+	}
+	return "Pulled " + image, nil
+}
+
+// PushImage runs until it is cancelled, so that a push job can be cancelled.
+func (s *stubService) PushImage(ctx context.Context, reference string, opts docker.PushOptions) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (s *stubService) MirrorImage(ctx context.Context, source, target string, opts docker.MirrorOptions) (string, error) {
+	return "Mirrored " + source + " to " + target, nil
+}
+
+func (s *stubService) BuildImage(ctx context.Context, buildContext io.Reader, spec docker.BuildSpec, progress func(jsonmessage.JSONMessage)) (string, error) {
+	return "sha256:built", nil
+}
+
+func (s *stubService) TagImage(ctx context.Context, source, target string) (string, error) {
+	return "Tagged " + source + " as " + target, nil
+}
+
+func (s *stubService) UntagImage(ctx context.Context, reference string) (string, error) {
+	return "Untagged " + reference, nil
+}
+
+func (s *stubService) SaveImages(ctx context.Context, images []string, w io.Writer) error {
+	_, err := io.WriteString(w, "tar")
+	return err
+}
+
+func (s *stubService) LoadImages(ctx context.Context, tarball io.Reader) ([]string, error) {
+	return []string{"nginx:latest"}, nil
+}
+
+func (s *stubService) ImportImage(ctx context.Context, tarball io.Reader, opts docker.ImportOptions) (string, error) {
+	return "sha256:imported", nil
+}
+
+func (s *stubService) RemoveImage(ctx context.Context, imageID string, protection *docker.ProtectionPolicy) (string, error) {
+	return "Image " + imageID + " removed", nil
+}
+
+func (s *stubService) RemoveAllImages(ctx context.Context, protection *docker.ProtectionPolicy) ([]string, []docker.SkippedResource, error) {
+	return []string{"Image sha256:img removed"}, []docker.SkippedResource{}, nil
+}
+
+func (s *stubService) RemoveAllDanglingImages(ctx context.Context, protection *docker.ProtectionPolicy) ([]string, []docker.SkippedResource, error) {
+	return []string{"Image sha256:old removed"}, []docker.SkippedResource{}, nil
+}
+
+func (s *stubService) ListVolumes(ctx context.Context, filter filters.Args) ([]*types.Volume, error) {
+	return []*types.Volume{{Name: "data", Driver: "local", Mountpoint: "/var/lib/docker/volumes/data", CreatedAt: "2024-01-01T00:00:00Z"}}, nil
+}
+
+func (s *stubService) InspectVolume(ctx context.Context, volumeName string) (*types.Volume, error) {
+	return &types.Volume{Name: volumeName, Driver: "local"}, nil
+}
+
+func (s *stubService) ListContainersAttachedToVolume(ctx context.Context, volumeName string) ([]string, error) {
+	return []string{"aaa"}, nil
+}
+
+func (s *stubService) RemoveVolume(ctx context.Context, volumeName string, protection *docker.ProtectionPolicy) (string, error) {
+	return "Volume " + volumeName + " removed", nil
+}
+
+func (s *stubService) ListNetworks(ctx context.Context, filter filters.Args) ([]types.NetworkResource, error) {
+	return []types.NetworkResource{{ID: "net1", Name: "shop_default", Driver: "bridge", Created: time.Unix(100, 0)}}, nil
+}
+
+func (s *stubService) InspectNetwork(ctx context.Context, networkID string) (map[string]interface{}, error) {
+	return map[string]interface{}{"Id": networkID, "Name": "shop_default"}, nil
+}
+
+func (s *stubService) ListContainersInNetwork(ctx context.Context, networkID string) ([]docker.NetworkContainer, error) {
+	return []docker.NetworkContainer{{ContainerID: "aaa", Name: "web"}}, nil
+}
+
+func (s *stubService) RemoveNetwork(ctx context.Context, networkID string, protection *docker.ProtectionPolicy) (string, error) {
+	return "Network " + networkID + " removed", nil
+}
+
+func (s *stubService) project() docker.Project {
+	return docker.Project{
+		Name: "shop", Status: "running(1)", Running: 1, Total: 1,
+		Services: []docker.ProjectService{{Name: "web", Status: "running", DependsOn: []string{}, Containers: []docker.ProjectContainer{
+			{ID: "aaa", Name: "web", Image: "nginx", State: "running"},
+		}}},
+		Networks: []string{"shop_default"},
+		Volumes:  []string{},
+	}
+}
+
+func (s *stubService) ListProjects(ctx context.Context, inScope func(labels map[string]string) bool) ([]docker.Project, error) {
+	return []docker.Project{s.project()}, nil
+}
+
+func (s *stubService) InspectProject(ctx context.Context, name string, inScope func(labels map[string]string) bool) (docker.Project, error) {
+	if name != "shop" {
+		return docker.Project{}, &docker.NotFoundError{Kind: "project", ID: name}
+	}
+	return s.project(), nil
+}
+
+func (s *stubService) projectAction(name, action string, code docker.ResultCode) (docker.ProjectActionResult, error) {
+	if _, err := s.InspectProject(context.Background(), name, nil); err != nil {
+		return docker.ProjectActionResult{}, err
+	}
+	return docker.ProjectActionResult{Project: name, Action: action, Steps: []docker.ProjectStep{
+		{Resource: "container", ID: "aaa", Name: "web", Service: "web", Image: "nginx", Code: code, Message: "Container " + string(code)},
+	}}, nil
+}
+
+func (s *stubService) StartProject(ctx context.Context, name string) (docker.ProjectActionResult, error) {
+	return s.projectAction(name, "start", docker.ResultStarted)
+}
+
+func (s *stubService) StopProject(ctx context.Context, name string, opts docker.ProjectActionOptions) (docker.ProjectActionResult, error) {
+	return s.projectAction(name, "stop", docker.ResultStopped)
+}
+
+func (s *stubService) RestartProject(ctx context.Context, name string, opts docker.ProjectActionOptions) (docker.ProjectActionResult, error) {
+	return s.projectAction(name, "restart", docker.ResultRestarted)
+}
+
+func (s *stubService) DownProject(ctx context.Context, name string, opts docker.ProjectActionOptions) (docker.ProjectActionResult, error) {
+	return s.projectAction(name, "down", docker.ResultRemoved)
+}
+
+// testAPI is the API served against stub daemons.
+type testAPI struct {
+	router  http.Handler
+	history db.HistoryStore
+	audit   db.AuditStore
+	jobs    *docker.JobManager
+}
+
+// newTestAPI serves the API against stub as the only host. Authentication
+// is enabled when authConfig names users or tokens.
+func newTestAPI(t *testing.T, stub docker.Service, authConfig config.AuthConfig) *testAPI {
+	t.Helper()
+	return newProtectedTestAPI(t, stub, authConfig, nil)
+}
+
+// newProtectedTestAPI is newTestAPI with deletion protection rules.
+func newProtectedTestAPI(t *testing.T, stub docker.Service, authConfig config.AuthConfig, rules []config.ProtectionRule) *testAPI {
+	t.Helper()
+	authenticator, err := auth.NewAuthenticator(authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := docker.NewHostRegistry(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hosts.Add("local", stub); err != nil {
+		t.Fatal(err)
+	}
+	audit, err := db.NewFileAuditStore(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	protection, err := docker.NewProtectionPolicy(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &testAPI{history: db.NewMemoryHistoryStore(0, 0), audit: audit, jobs: docker.NewJobManager()}
+	credentials := docker.NewCredentialStore(nil)
+	api.router = SetupRouter(config.HTTPConfig{}, authenticator, hosts, docker.NewEventHub(hosts), api.jobs, credentials, protection, api.history, api.audit)
+	return api
+}
+
+// do serves one request with a JSON body, if any, and returns the recorded response.
+func (api *testAPI) do(method, target, body string) *httptest.ResponseRecorder {
+	return api.doAs("", method, target, body)
+}
+
+// doAs serves one request authenticated by token.
+func (api *testAPI) doAs(token, method, target, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListContainersHandler(t *testing.T) {
+	api := newTestAPI(t, newStubService(), config.AuthConfig{})
+
+	rec := api.do(http.MethodGet, "/api/v1/containers?all=true&sort=name", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var page ContainerPage
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("got %d of %d containers, want 2 of 2", len(page.Items), page.Total)
+	}
+	if page.Items[0].Names[0] != "db" || page.Items[0].Host != "local" {
+		t.Errorf("first container = %+v, want db on local", page.Items[0])
+	}
+}
+
+func TestListContainersHandlerLegacyRunningOnly(t *testing.T) {
+	api := newTestAPI(t, newStubService(), config.AuthConfig{})
+
+	rec := api.do(http.MethodGet, "/containers", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var containers []ContainerResponse
+	if err := json.NewDecoder(rec.Body).Decode(&containers); err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 || containers[0].ID != "aaa" {
+		t.Errorf("containers = %+v, want only aaa", containers)
+	}
+}
+
+func TestStartContainerHandler(t *testing.T) {
+	stub := newStubService()
+	api := newTestAPI(t, stub, config.AuthConfig{})
+
+	rec := api.do(http.MethodPost, "/api/v1/containers/bbb/start", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if len(stub.started) != 1 || stub.started[0] != "bbb" {
+		t.Errorf("started = %v, want [bbb]", stub.started)
+	}
+
+	records, _, err := api.history.Find(context.Background(), models.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Action != "start" || records[0].ContainerNames[0] != "db" {
+		t.Errorf("history = %+v, want one start of db", records)
+	}
+
+	rec = api.do(http.MethodPost, "/api/v1/containers/missing/start", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for a missing container = %d, want 404", rec.Code)
+	}
+}
+
+func TestRemoveProtectedContainer(t *testing.T) {
+	api := newProtectedTestAPI(t, newStubService(), config.AuthConfig{}, []config.ProtectionRule{{Name: "databases", Names: []string{"db"}}})
+
+	rec := api.do(http.MethodDelete, "/api/v1/containers/bbb", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"rule":"databases"`) {
+		t.Fatalf("status = %d, body %s; want 403 naming the rule", rec.Code, rec.Body)
+	}
+
+	rec = api.do(http.MethodDelete, "/api/v1/containers/bbb?override=true", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status for an override without a reason = %d, want 400", rec.Code)
+	}
+
+	rec = api.do(http.MethodDelete, "/api/v1/containers/bbb?override=true&reason=migrated", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status for an override = %d, body %s", rec.Code, rec.Body)
+	}
+	records, _, err := api.history.Find(context.Background(), models.HistoryFilter{Action: "remove"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 || !strings.Contains(records[0].Message, "protection overridden: migrated") {
+		t.Errorf("history = %+v, want the override reason", records)
+	}
+
+	rec = api.do(http.MethodDelete, "/api/v1/containers/aaa", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("status for an unprotected container = %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestBuildImageContextPathNeedsBuildRoot(t *testing.T) {
+	api := newTestAPI(t, newStubService(), config.AuthConfig{})
+
+	rec := api.do(http.MethodPost, "/api/v1/images/build", `{"context_path":"/etc","tags":["app:1"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400; body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestCreateContainerPassesRegistryCredentials(t *testing.T) {
+	stub := newStubService()
+	api := newTestAPI(t, stub, config.AuthConfig{})
+
+	body := `{"image":"registry.example.com/app:1","auth":{"server_address":"registry.example.com","username":"ci","password":"secret"}}`
+	if rec := api.do(http.MethodPost, "/api/v1/containers", body); rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if len(stub.created) != 1 {
+		t.Fatalf("created %d containers, want 1", len(stub.created))
+	}
+
+	want, err := docker.RegistryAuth{ServerAddress: "registry.example.com", Username: "ci", Password: "secret"}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec := stub.created[0]; spec.Image != "registry.example.com/app:1" || spec.RegistryAuth != want {
+		t.Errorf("created %+v, want the image pulled with the encoded credentials", spec)
+	}
+
+	if rec := api.do(http.MethodPost, "/api/v1/containers", `{"image":"app:1","credential":"missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown stored credential: status %d, want 404", rec.Code)
+	}
+}
+
+func TestRegistryRequestsReadTheURL(t *testing.T) {
+	api := newTestAPI(t, newStubService(), config.AuthConfig{})
+
+	if rec := api.do(http.MethodPost, "/api/v1/images/pull?image=nginx:latest", ""); rec.Code != http.StatusOK {
+		t.Errorf("pull named in the query: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := api.do(http.MethodPost, "/api/v1/images/pull?image=nginx:latest", `{"image":"redis:7"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("pull naming different images: status %d, want 400", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, "/api/v1/images/mirror/jobs?source=a/app:1", `{"source":"b/app:1","target":"c/app:1"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("mirror naming different sources: status %d, want 400", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, "/api/v1/containers?name=web2", `{"image":"nginx","name":"web3"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create naming different containers: status %d, want 400", rec.Code)
+	}
+}
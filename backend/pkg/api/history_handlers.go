@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/docker"
+	"Docker_Management/pkg/models"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// HistoryResponse is one page of lifecycle history.
+type HistoryResponse struct {
+	Total   int64                  `json:"total"`
+	Offset  int64                  `json:"offset"`
+	Limit   int64                  `json:"limit"`
+	Records []models.HistoryRecord `json:"records"`
+}
+
+// actorFor identifies who made the request: the authenticated user or token,
+// or the client address when authentication is disabled.
+func actorFor(r *http.Request) string {
+	if principal := principalFor(r); principal.Method != auth.Anonymous.Method {
+		return principal.Name
+	}
+	return clientAddr(r)
+}
+
+// clientAddr is the IP address the request came from.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// newHistoryRecord starts an API history record for an action on the selected host.
+func (h *Handlers) newHistoryRecord(r *http.Request, action string) models.HistoryRecord {
+	return models.HistoryRecord{
+		Host:   h.hostName(r),
+		Action: action,
+		Actor:  actorFor(r),
+		Source: models.HistorySourceAPI,
+	}
+}
+
+// newContainerHistoryRecord starts a history record for a container action. The
+// container is looked up now so that its names and image survive its removal.
+func (h *Handlers) newContainerHistoryRecord(r *http.Request, dockerService docker.Service, action, containerID string) models.HistoryRecord {
+	record := h.newHistoryRecord(r, action)
+	record.ContainerID = containerID
+
+	if containerJSON, err := dockerService.InspectContainer(r.Context(), containerID); err == nil {
+		record.ContainerID = containerJSON.ID
+		record.ContainerNames = []string{strings.TrimPrefix(containerJSON.Name, "/")}
+		if containerJSON.Config != nil {
+			record.Image = containerJSON.Config.Image
+		}
+	}
+	return record
+}
+
+// saveHistory completes record with the outcome of the action and stores it.
+// Failures to store are logged so they never fail the request itself.
+func (h *Handlers) saveHistory(record models.HistoryRecord, message string, actionErr error) {
+	record.Timestamp = time.Now().UTC()
+	record.Outcome = models.OutcomeSuccess
+	record.Message = message
+	if actionErr != nil {
+		record.Outcome = models.OutcomeFailure
+		record.Message = actionErr.Error()
+	}
+
+	// Use a fresh context so the record is kept even if the client has gone away
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.history.Insert(ctx, record); err != nil {
+		log.Printf("Failed to record %s history: %v", record.Action, err)
+	}
+}
+
+// logPage is the time range and page requested from the history or audit log.
+type logPage struct {
+	since, until time.Time
+	skip, limit  int64
+}
+
+// parseLogPage reads since and until (RFC 3339), offset and limit (default 50,
+// at most 500) from the query string.
+func parseLogPage(query url.Values) (logPage, error) {
+	page := logPage{limit: defaultHistoryLimit}
+
+	var err error
+	if value := query.Get("since"); value != "" {
+		if page.since, err = time.Parse(time.RFC3339, value); err != nil {
+			return page, errors.New("Invalid since time, expected RFC 3339")
+		}
+	}
+	if value := query.Get("until"); value != "" {
+		if page.until, err = time.Parse(time.RFC3339, value); err != nil {
+			return page, errors.New("Invalid until time, expected RFC 3339")
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if page.skip, err = strconv.ParseInt(value, 10, 64); err != nil || page.skip < 0 {
+			return page, errors.New("Offset must be a non-negative number")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if page.limit, err = strconv.ParseInt(value, 10, 64); err != nil || page.limit <= 0 {
+			return page, errors.New("Limit must be a positive number")
+		}
+		if page.limit > maxHistoryLimit {
+			page.limit = maxHistoryLimit
+		}
+	}
+	return page, nil
+}
+
+// ListHistoryHandler pages through the lifecycle history, newest first.
+// Query parameters: host, action, container, image, actor, source, outcome,
+// since and until (RFC 3339), offset and limit (default 50, at most 500).
+func (h *Handlers) ListHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.HistoryFilter{
+		Host:        query.Get("host"),
+		Action:      query.Get("action"),
+		ContainerID: query.Get("container"),
+		Image:       query.Get("image"),
+		Actor:       query.Get("actor"),
+		Source:      query.Get("source"),
+		Outcome:     query.Get("outcome"),
+	}
+	if filter.Host == docker.AllHosts {
+		filter.Host = ""
+	}
+	// Callers scoped by label only see their own actions
+	if principalFor(r).Scoped() {
+		filter.Actor = actorFor(r)
+	}
+
+	page, err := parseLogPage(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	filter.Since, filter.Until, filter.Skip, filter.Limit = page.since, page.until, page.skip, page.limit
+
+	records, total, err := h.history.Find(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve history", err)
+		return
+	}
+
+	response := HistoryResponse{
+		Total:   total,
+		Offset:  filter.Skip,
+		Limit:   filter.Limit,
+		Records: records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"Docker_Management/pkg/docker"
+)
+
+// hostTarget pairs a host name with the DockerService that manages it.
+type hostTarget struct {
+	name   string
+	docker docker.Service
+}
+
+// dockerFor resolves the DockerService selected by the "host" query parameter.
+func (h *Handlers) dockerFor(r *http.Request) (docker.Service, error) {
+	return h.hosts.Get(r.URL.Query().Get("host"))
+}
+
+// hostName returns the name of the host selected by the "host" query parameter.
+func (h *Handlers) hostName(r *http.Request) string {
+	if name := r.URL.Query().Get("host"); name != "" {
+		return name
+	}
+	return h.hosts.DefaultHost()
+}
+
+// targetHosts resolves the "host" query parameter for list views. host=all
+// selects every registered host; otherwise a single host is returned.
+func (h *Handlers) targetHosts(r *http.Request) ([]hostTarget, error) {
+	name := r.URL.Query().Get("host")
+	if name == docker.AllHosts {
+		var targets []hostTarget
+		for _, hostName := range h.hosts.Names() {
+			service, err := h.hosts.Get(hostName)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, hostTarget{name: hostName, docker: service})
+		}
+		return targets, nil
+	}
+
+	if name == "" {
+		name = h.hosts.DefaultHost()
+	}
+	service, err := h.hosts.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return []hostTarget{{name: name, docker: service}}, nil
+}
+
+// ListHostsHandler lists the registered Docker hosts
+func (h *Handlers) ListHostsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.hosts.Hosts())
+}
@@ -1,17 +1,20 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
 	"Docker_Management/pkg/docker"
+
+	"github.com/docker/docker/api/types"
 )
 
 type ImageResponse struct {
+	Host    string `json:"host"` // Docker host the image is stored on
 	ID      string `json:"id"`
 	Name    string `json:"name"` // Repository name
 	Tag     string `json:"tag"`  // Image tag
@@ -19,17 +22,73 @@ type ImageResponse struct {
 	Size    string `json:"size"` // Size in MB
 }
 
-func ListImagesHandler(w http.ResponseWriter, r *http.Request) {
-	// Call the ListImages function
-	images, err := docker.ListImages()
+// ImagePage is a page of the image list
+type ImagePage struct {
+	Total      int             `json:"total"` // Matching images in all pages
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Items      []ImageResponse `json:"items"`
+}
+
+// hostImage is an image as listed by the daemon of host
+type hostImage struct {
+	host string
+	types.ImageSummary
+}
+
+func imageSortKey(img hostImage, field string) sortKey {
+	key := sortKey{ID: img.host + "/" + img.ID}
+	switch field {
+	case "created":
+		key.Number = img.Created
+	case "size":
+		key.Number = img.Size
+	case "name":
+		key.Text = img.ID
+		if len(img.RepoTags) > 0 {
+			key.Text = img.RepoTags[0]
+		}
+	}
+	return key
+}
+
+// ListImagesHandler lists the images. The label, dangling and name query
+// parameters filter the list on the daemon.
+func (h *Handlers) ListImagesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := parseListQuery(r, "created", "size", "name")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	targets, err := h.targetHosts(r)
 	if err != nil {
-		http.Error(w, "Failed to list images: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
+	filter := listFilters(r.URL.Query(), imageFilters)
+	principal := principalFor(r)
+	var rows []hostImage
+	for _, target := range targets {
+		// Call the ListImages function
+		images, err := target.docker.ListImages(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to list images on host "+target.name, err)
+			return
+		}
+		for _, img := range images {
+			// Callers scoped by label only see their own images
+			if !principal.InScope(img.Labels) {
+				continue
+			}
+			rows = append(rows, hostImage{host: target.name, ImageSummary: img})
+		}
+	}
+
 	// Prepare the response
-	var response []ImageResponse
-	for _, img := range images {
+	page, total, next := paginate(rows, list, imageSortKey)
+	response := []ImageResponse{}
+	for _, img := range page {
 		// Initialize name and tag
 		var name, tag string
 
@@ -53,6 +112,7 @@ func ListImagesHandler(w http.ResponseWriter, r *http.Request) {
 
 		// Create the ImageResponse
 		response = append(response, ImageResponse{
+			Host:    img.host,
 			ID:      img.ID,
 			Name:    name,
 			Tag:     tag,
@@ -60,14 +120,7 @@ func ListImagesHandler(w http.ResponseWriter, r *http.Request) {
 			Size:    sizeFormatted,                                  // Use formatted size
 		})
 	}
-
-	// Set the response content type to JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	json.NewEncoder(w).Encode(response)
+	writeList(w, r, response, ImagePage{Total: total, NextCursor: next, Items: response})
 }
 
 // Helper function to format size
@@ -76,188 +129,506 @@ func formatSize(size float64) string {
 }
 
 type DanglingImageResponse struct {
+	Host    string `json:"host"` // Docker host the image is stored on
 	ID      string `json:"id"`
 	Created string `json:"created"`
 	Size    string `json:"size"` // Size in MB
 }
 
-// ListDanglingImagesHandler handles the HTTP request to list all dangling images
-func ListDanglingImagesHandler(w http.ResponseWriter, r *http.Request) {
-	// Call the ListDanglingImages function
-	images, err := docker.ListDanglingImages()
+// DanglingImagePage is a page of the dangling image list
+type DanglingImagePage struct {
+	Total      int                     `json:"total"` // Matching images in all pages
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	Items      []DanglingImageResponse `json:"items"`
+}
+
+// ListDanglingImagesHandler handles the HTTP request to list all dangling
+// images. The label query parameter filters the list on the daemon.
+func (h *Handlers) ListDanglingImagesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := parseListQuery(r, "created", "size", "name")
 	if err != nil {
-		http.Error(w, "Failed to list dangling images: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	if len(images) == 0 {
-		response := map[string]string{"message": "No Dangling images"}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
+
+	filter := listFilters(r.URL.Query(), danglingImageFilters)
+	principal := principalFor(r)
+	var rows []hostImage
+	for _, target := range targets {
+		// Call the ListDanglingImages function
+		images, err := target.docker.ListDanglingImages(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to list dangling images on host "+target.name, err)
+			return
+		}
+		for _, img := range images {
+			// Callers scoped by label only see their own images
+			if !principal.InScope(img.Labels) {
+				continue
+			}
+			rows = append(rows, hostImage{host: target.name, ImageSummary: img})
+		}
+	}
+
 	// Prepare the response
-	var response []DanglingImageResponse
-	for _, img := range images {
+	page, total, next := paginate(rows, list, imageSortKey)
+	response := []DanglingImageResponse{}
+	for _, img := range page {
 		// Convert size from bytes to MB
 		sizeInMB := float64(img.Size) / (1024 * 1024) // Convert bytes to MB
 		sizeFormatted := fmt.Sprintf("%.0f MB", sizeInMB)
 
 		// Create the DanglingImageResponse
 		response = append(response, DanglingImageResponse{
+			Host:    img.host,
 			ID:      img.ID,
 			Created: time.Unix(img.Created, 0).Format(time.RFC3339), // Format the created time
 			Size:    sizeFormatted,                                  // Use formatted size
 		})
 	}
 
-	// Set the response content type to JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	json.NewEncoder(w).Encode(response)
+	// The legacy route has always answered an empty list with a message
+	if len(response) == 0 && isLegacyRoute(r) {
+		response := map[string]string{"message": "No Dangling images"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	writeList(w, r, response, DanglingImagePage{Total: total, NextCursor: next, Items: response})
 }
 
 type RemoveImageRequest struct {
 	ID string `json:"id"` // ID of the image to remove
+	ProtectionOverride
 }
 
 // RemoveImageHandler handles the HTTP request to remove a Docker image
-func RemoveImageHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) RemoveImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var req RemoveImageRequest
 
 	// Decode the request body
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	protection, err := h.protectionFor(req.ProtectionOverride)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
 	// Call the RemoveImage function
-	message, err := docker.RemoveImage(req.ID)
+	record := h.newHistoryRecord(r, "remove")
+	record.Image = req.ID
+	message, err := dockerService.RemoveImage(r.Context(), req.ID, protection)
+	h.saveHistory(record, overrideNote(message, req.ProtectionOverride), err)
 	if err != nil {
-		http.Error(w, "Failed to remove image: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to remove image", err)
 		return
 	}
 
 	// Set the response content type to JSON and return success message
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	json.NewEncoder(w).Encode(map[string]string{"message": message})
+	json.NewEncoder(w).Encode(MessageResponse{Message: message})
 }
 
-func RemoveAllImagesHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) RemoveAllImagesHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	// Call the RemoveAllImages function
-	results, err := docker.RemoveAllImages()
+	record := h.newHistoryRecord(r, "prune")
+	results, skipped, err := dockerService.RemoveAllImages(r.Context(), h.protection)
+	h.saveHistory(record, "Images: "+strings.Join(results, "; "), err)
 	if err != nil {
-		http.Error(w, "Failed to remove images: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to remove images", err)
 		return
 	}
 
 	// Set the response content type to JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 	// Return the result messages in JSON format
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Images Deleted",
-		"details": results,
+	json.NewEncoder(w).Encode(BulkRemoveResponse{
+		Message: "Images Deleted",
+		Details: results,
+		Skipped: skipped,
 	})
 }
 
-func InspectImageHandler(w http.ResponseWriter, r *http.Request) {
-	// Read the request body
-	body, err := ioutil.ReadAll(r.Body)
+func (h *Handlers) InspectImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	// Parse the JSON body
-	var requestData struct {
-		ID string `json:"id"`
-	}
-
-	if err := json.Unmarshal(body, &requestData); err != nil {
-		http.Error(w, "Failed to parse JSON body", http.StatusBadRequest)
+	// Decode the request from the body and URL
+	var requestData RequestBody
+	if err := decodeRequest(r, &requestData); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the InspectImage function
-	imageDetails, err := docker.InspectImage(requestData.ID)
+	imageDetails, err := dockerService.InspectImage(r.Context(), requestData.ID)
 	if err != nil {
-		http.Error(w, "Failed to inspect image: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to inspect image", err)
 		return
 	}
 
 	// Set the response content type to JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 	// Return the inspected image details in JSON format
 	json.NewEncoder(w).Encode(imageDetails)
 }
 
-func PullImageHandler(w http.ResponseWriter, r *http.Request) {
-    // Read the request body
-    body, err := ioutil.ReadAll(r.Body)
-    if err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
-        return
-    }
-
-    // Parse the JSON input
-    var requestData struct {
-        Image string `json:"image"`
-    }
-    err = json.Unmarshal(body, &requestData)
-    if err != nil || requestData.Image == "" {
-        http.Error(w, "Invalid input format. Expected {image: \"image_name:version\"}", http.StatusBadRequest)
-        return
-    }
-
-    // Pull the image
-    result, err := docker.PullImage(requestData.Image)
-    if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
-        return
-    }
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-    // Send the success response
-    response := map[string]string{"message": result}
-    json.NewEncoder(w).Encode(response)
+// RegistryCredentials selects the credentials for a registry operation: either
+// inline credentials or the name of a stored credential set.
+type RegistryCredentials struct {
+	Auth       *docker.RegistryAuth `json:"auth,omitempty"`
+	Credential string               `json:"credential,omitempty"`
+}
+
+// registryAuth resolves and encodes the requested credentials. No credentials yields "".
+func (h *Handlers) registryAuth(credentials RegistryCredentials) (string, error) {
+	if credentials.Auth != nil {
+		return credentials.Auth.Encode()
+	}
+	if credentials.Credential != "" {
+		auth, err := h.credentials.Get(credentials.Credential)
+		if err != nil {
+			return "", err
+		}
+		return auth.Encode()
+	}
+	return "", nil
+}
+
+type PullImageRequest struct {
+	Image string `json:"image"`
+	RegistryCredentials
+}
+
+// decodePullImageRequest reads a pull request from the body and URL and
+// resolves its credentials.
+func (h *Handlers) decodePullImageRequest(w http.ResponseWriter, r *http.Request) (PullImageRequest, string, bool) {
+	var requestData PullImageRequest
+	err := decodeRequest(r, &requestData)
+	if err != nil || requestData.Image == "" {
+		writeError(w, http.StatusBadRequest, "Invalid input format. Expected {image: \"image_name:version\"}", err)
+		return requestData, "", false
+	}
+
+	registryAuth, err := h.registryAuth(requestData.RegistryCredentials)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return requestData, "", false
+	}
+	return requestData, registryAuth, true
+}
+
+func (h *Handlers) PullImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Parse the JSON input
+	requestData, registryAuth, ok := h.decodePullImageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Pull the image
+	record := h.newHistoryRecord(r, "pull")
+	record.Image = requestData.Image
+	result, err := dockerService.PullImage(r.Context(), requestData.Image, docker.PullOptions{RegistryAuth: registryAuth})
+	h.saveHistory(record, result, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	// Send the success response
+	response := MessageResponse{Message: result}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// PullImageJobHandler starts pulling an image in the background and returns
+// the job, whose progress can be followed on /jobs/stream.
+func (h *Handlers) PullImageJobHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Parse the JSON input
+	requestData, registryAuth, ok := h.decodePullImageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Pull the image in the background
+	record := h.newHistoryRecord(r, "pull")
+	record.Image = requestData.Image
+	job := h.jobs.Start("pull", record.Host, requestData.Image, record.Actor, func(ctx context.Context, job *docker.Job) error {
+		result, err := dockerService.PullImage(ctx, requestData.Image, docker.PullOptions{
+			RegistryAuth: registryAuth,
+			Progress:     job.Progress,
+		})
+		h.saveHistory(record, result, err)
+		return err
+	})
+
+	h.writeJob(w, http.StatusAccepted, job)
+}
+
+func (h *Handlers) RemoveAllDanglingImagesHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Call the RemoveAllDanglingImages function
+	record := h.newHistoryRecord(r, "prune")
+	results, skipped, err := dockerService.RemoveAllDanglingImages(r.Context(), h.protection)
+	h.saveHistory(record, "Dangling images: "+strings.Join(results, "; "), err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to remove dangling images", err)
+		return
+	}
+
+	// Set the response content type to JSON
+	w.Header().Set("Content-Type", "application/json")
+	// Return the result messages in JSON format
+	json.NewEncoder(w).Encode(BulkRemoveResponse{
+		Message: "Dangling Images Deletion",
+		Details: results,
+		Skipped: skipped,
+	})
+}
+
+type TagImageRequest struct {
+	Source string `json:"source"` // Existing image ID or reference
+	Target string `json:"target"` // New reference, e.g. registry.example.com/app:1.2
 }
 
-func RemoveAllDanglingImagesHandler(w http.ResponseWriter, r *http.Request) {
-    // Call the RemoveAllDanglingImages function
-    results, err := docker.RemoveAllDanglingImages()
-    if err != nil {
-        http.Error(w, "Failed to remove dangling images: "+err.Error(), http.StatusInternalServerError)
-        return
-    }
-
-    // Set the response content type to JSON
-    w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-    // Return the result messages in JSON format
-    json.NewEncoder(w).Encode(map[string]interface{}{
-        "message": "Dangling Images Deletion",
-        "details": results,
-    })
-}
\ No newline at end of file
+// TagImageHandler adds a new reference to an image
+func (h *Handlers) TagImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var req TagImageRequest
+
+	// Decode the request body
+	if err := decodeRequest(r, &req); err != nil || req.Source == "" || req.Target == "" {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Expected {source, target}", err)
+		return
+	}
+
+	record := h.newHistoryRecord(r, "tag")
+	record.Image = req.Target
+	message, err := dockerService.TagImage(r.Context(), req.Source, req.Target)
+	h.saveHistory(record, message, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to tag image", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MessageResponse{Message: message})
+}
+
+type UntagImageRequest struct {
+	Image string `json:"image"` // Reference to remove
+}
+
+// UntagImageHandler removes one reference from an image without deleting the image
+func (h *Handlers) UntagImageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var req UntagImageRequest
+
+	// Decode the request body
+	if err := decodeRequest(r, &req); err != nil || req.Image == "" {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Expected {image}", err)
+		return
+	}
+
+	record := h.newHistoryRecord(r, "untag")
+	record.Image = req.Image
+	message, err := dockerService.UntagImage(r.Context(), req.Image)
+	h.saveHistory(record, message, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to untag image", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MessageResponse{Message: message})
+}
+
+type PushImageRequest struct {
+	Image string `json:"image"`
+	RegistryCredentials
+}
+
+// PushImageJobHandler starts pushing an image in the background and returns
+// the job, whose progress can be followed on /jobs/stream.
+func (h *Handlers) PushImageJobHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var req PushImageRequest
+
+	// Decode the request body
+	if err := decodeRequest(r, &req); err != nil || req.Image == "" {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Expected {image}", err)
+		return
+	}
+	registryAuth, err := h.registryAuth(req.RegistryCredentials)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Push the image in the background
+	record := h.newHistoryRecord(r, "push")
+	record.Image = req.Image
+	job := h.jobs.Start("push", record.Host, req.Image, record.Actor, func(ctx context.Context, job *docker.Job) error {
+		result, err := dockerService.PushImage(ctx, req.Image, docker.PushOptions{
+			RegistryAuth: registryAuth,
+			Progress:     job.Progress,
+		})
+		h.saveHistory(record, result, err)
+		return err
+	})
+
+	h.writeJob(w, http.StatusAccepted, job)
+}
+
+type MirrorImageRequest struct {
+	Source     string              `json:"source"` // e.g. staging.example.com/app:1.2
+	Target     string              `json:"target"` // e.g. prod.example.com/app:1.2
+	SourceAuth RegistryCredentials `json:"source_auth"`
+	TargetAuth RegistryCredentials `json:"target_auth"`
+	Keep       bool                `json:"keep"` // Leave the pulled source and the target tag on this host
+}
+
+// MirrorImageJobHandler starts copying an image from one registry to another
+// in the background and returns the job.
+func (h *Handlers) MirrorImageJobHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var req MirrorImageRequest
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &req); err != nil || req.Source == "" || req.Target == "" {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Expected {source, target}", err)
+		return
+	}
+	sourceAuth, err := h.registryAuth(req.SourceAuth)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+	targetAuth, err := h.registryAuth(req.TargetAuth)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Mirror the image in the background
+	record := h.newHistoryRecord(r, "mirror")
+	record.Image = req.Target
+	job := h.jobs.Start("mirror", record.Host, req.Target, record.Actor, func(ctx context.Context, job *docker.Job) error {
+		result, err := dockerService.MirrorImage(ctx, req.Source, req.Target, docker.MirrorOptions{
+			SourceAuth: sourceAuth,
+			TargetAuth: targetAuth,
+			Keep:       req.Keep,
+			Progress:   job.Progress,
+		})
+		h.saveHistory(record, result, err)
+		return err
+	})
+
+	h.writeJob(w, http.StatusAccepted, job)
+}
+
+// ImageHistoryHandler lists the layers of the image given by the id query
+// parameter with the command that created each
+func (h *Handlers) ImageHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	imageID := pathParam(r, "id")
+	if imageID == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Image ID is required")
+		return
+	}
+
+	layers, err := dockerService.ImageHistory(r.Context(), imageID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve image history", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(layers)
+}
+
+// ImageUsageHandler breaks the disk usage of local images down into shared and unique sizes
+func (h *Handlers) ImageUsageHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	report, err := dockerService.ImageUsage(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve image usage", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"Docker_Management/pkg/docker"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// writeJob responds with the current state of job.
+func (h *Handlers) writeJob(w http.ResponseWriter, status int, job *docker.Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(job.Info())
+}
+
+// jobFor returns the job with the given ID. Callers scoped by label only
+// reach the jobs they started; other jobs are reported as missing.
+func (h *Handlers) jobFor(r *http.Request, id string) (*docker.Job, error) {
+	job, err := h.jobs.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if principalFor(r).Scoped() && job.Info().Actor != actorFor(r) {
+		return nil, &docker.NotFoundError{Kind: "job", ID: id}
+	}
+	return job, nil
+}
+
+// ListJobsHandler lists the background jobs, newest first
+func (h *Handlers) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobs := h.jobs.List()
+
+	// Callers scoped by label only see the jobs they started
+	if principalFor(r).Scoped() {
+		actor := actorFor(r)
+		own := []docker.JobInfo{}
+		for _, job := range jobs {
+			if job.Actor == actor {
+				own = append(own, job)
+			}
+		}
+		jobs = own
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// InspectJobHandler returns the state of the job given by the id query parameter
+func (h *Handlers) InspectJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobFor(r, pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	h.writeJob(w, http.StatusOK, job)
+}
+
+// StreamJobHandler streams the progress of the job given by the id query
+// parameter as "progress" Server-Sent Events, replaying from the start, and
+// ends with an "end" event holding the final job state.
+func (h *Handlers) StreamJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobFor(r, pathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// The request context is cancelled when the client disconnects, which ends the stream
+	err = job.Follow(r.Context(), func(message jsonmessage.JSONMessage) error {
+		return stream.SendJSON("progress", message)
+	})
+	if err != nil {
+		return
+	}
+	stream.SendJSON("end", job.Info())
+}
+
+// CancelJobHandler cancels a running job
+func (h *Handlers) CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	var requestBody RequestBody
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	job, err := h.jobFor(r, requestBody.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	if err := h.jobs.Cancel(requestBody.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	h.writeJob(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"Docker_Management/pkg/docker"
+)
+
+// ContainerActionRequest selects a container and carries the options of the
+// state change applied to it.
+type ContainerActionRequest struct {
+	ID        string `json:"id"`
+	Timeout   *int   `json:"timeout,omitempty"`   // Seconds to wait for the container to exit before killing it
+	Signal    string `json:"signal,omitempty"`    // Signal sent by kill, default SIGKILL
+	Name      string `json:"name,omitempty"`      // New name for rename
+	Condition string `json:"condition,omitempty"` // not-running, next-exit or removed for wait
+}
+
+// timeout returns the requested grace period, or nil to use the container's own stop timeout.
+func (req ContainerActionRequest) timeout() *time.Duration {
+	if req.Timeout == nil {
+		return nil
+	}
+	timeout := time.Duration(*req.Timeout) * time.Second
+	return &timeout
+}
+
+// containerAction is the body of the container state change handlers. It
+// decodes the request, applies action, records it in the history and responds
+// with the structured result.
+func (h *Handlers) containerAction(w http.ResponseWriter, r *http.Request, action string, apply func(context.Context, docker.Service, ContainerActionRequest) (docker.ActionResult, error)) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var requestBody ContainerActionRequest
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if requestBody.ID == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Container ID is required")
+		return
+	}
+
+	record := h.newContainerHistoryRecord(r, dockerService, action, requestBody.ID)
+	result, err := apply(r.Context(), dockerService, requestBody)
+	h.saveHistory(record, result.Message, err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to "+action+" container", err)
+		return
+	}
+
+	// Set the response content type to JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// RestartContainerHandler restarts a container, optionally with a grace period in seconds
+func (h *Handlers) RestartContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "restart", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.RestartContainer(ctx, req.ID, req.timeout())
+	})
+}
+
+// PauseContainerHandler pauses a running container
+func (h *Handlers) PauseContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "pause", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.PauseContainer(ctx, req.ID)
+	})
+}
+
+// UnpauseContainerHandler resumes a paused container
+func (h *Handlers) UnpauseContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "unpause", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.UnpauseContainer(ctx, req.ID)
+	})
+}
+
+// KillContainerHandler sends a signal to a running container
+func (h *Handlers) KillContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "kill", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.KillContainer(ctx, req.ID, req.Signal)
+	})
+}
+
+// RenameContainerHandler renames a container
+func (h *Handlers) RenameContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "rename", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.RenameContainer(ctx, req.ID, req.Name)
+	})
+}
+
+// WaitContainerHandler blocks until a container exits and returns its exit code
+func (h *Handlers) WaitContainerHandler(w http.ResponseWriter, r *http.Request) {
+	h.containerAction(w, r, "wait", func(ctx context.Context, dockerService docker.Service, req ContainerActionRequest) (docker.ActionResult, error) {
+		return dockerService.WaitContainer(ctx, req.ID, req.Condition)
+	})
+}
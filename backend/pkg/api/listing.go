@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+	defaultListSort  = "-created"
+)
+
+// Daemon filters each list route accepts, by query parameter. Values are
+// passed through unchanged, and repeated parameters match any of their values.
+var (
+	containerFilters     = map[string]string{"status": "status", "label": "label", "name": "name", "ancestor": "ancestor"}
+	imageFilters         = map[string]string{"label": "label", "dangling": "dangling", "name": "reference"}
+	danglingImageFilters = map[string]string{"label": "label"}
+	volumeFilters        = map[string]string{"label": "label", "name": "name", "dangling": "dangling", "driver": "driver"}
+	networkFilters       = map[string]string{"label": "label", "name": "name", "dangling": "dangling", "driver": "driver"}
+)
+
+// listFilters builds the daemon filters of a list request.
+func listFilters(query url.Values, accepted map[string]string) filters.Args {
+	args := filters.NewArgs()
+	for param, key := range accepted {
+		for _, value := range query[param] {
+			args.Add(key, value)
+		}
+	}
+	return args
+}
+
+// sortKey orders a row of a list: by the value of the requested field, then
+// by the host and ID of the resource, so that every row has its own place and
+// a cursor can point between rows.
+type sortKey struct {
+	Number int64  `json:"n,omitempty"` // created and size
+	Text   string `json:"t,omitempty"` // name
+	ID     string `json:"id"`
+}
+
+func (k sortKey) less(other sortKey) bool {
+	if k.Number != other.Number {
+		return k.Number < other.Number
+	}
+	if k.Text != other.Text {
+		return k.Text < other.Text
+	}
+	return k.ID < other.ID
+}
+
+// listCursor marks where a page ended. It is only valid for the same order.
+type listCursor struct {
+	Sort  string  `json:"sort"`
+	After sortKey `json:"after"`
+}
+
+// listQuery is the order and page requested from a list route.
+type listQuery struct {
+	field string // created, size or name
+	sort  string // field, with a leading - when descending
+	desc  bool
+	limit int // No limit when zero
+	after *sortKey
+}
+
+// parseListQuery reads sort (created, size or name, prefixed with - to
+// descend; default -created), limit and cursor from the query string. The
+// legacy routes, which predate pagination, have no default limit.
+func parseListQuery(r *http.Request, sortable ...string) (listQuery, error) {
+	query := r.URL.Query()
+	list := listQuery{sort: defaultListSort}
+	if !isLegacyRoute(r) {
+		list.limit = defaultListLimit
+	}
+
+	if value := query.Get("sort"); value != "" {
+		list.sort = value
+	}
+	list.desc = strings.HasPrefix(list.sort, "-")
+	list.field = strings.TrimPrefix(list.sort, "-")
+	valid := false
+	for _, field := range sortable {
+		valid = valid || field == list.field
+	}
+	if !valid {
+		return list, errors.New("Sort must be one of " + strings.Join(sortable, ", ") + ", optionally prefixed with -")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return list, errors.New("Limit must be a positive number")
+		}
+		list.limit = limit
+	}
+	if list.limit > maxListLimit {
+		list.limit = maxListLimit
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		var cursor listCursor
+		decoded, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || json.Unmarshal(decoded, &cursor) != nil {
+			return list, errors.New("Invalid cursor")
+		}
+		if cursor.Sort != list.sort {
+			return list, errors.New("The cursor belongs to a list sorted by " + cursor.Sort)
+		}
+		list.after = &cursor.After
+	}
+	return list, nil
+}
+
+// isLegacyRoute reports whether r came in on a deprecated unversioned route.
+func isLegacyRoute(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	return route != nil && strings.HasPrefix(route.GetName(), legacyRoutePrefix)
+}
+
+// paginate sorts rows by the requested order and returns the page following
+// the cursor, the number of rows in all pages, and the cursor of the next
+// page when there is one.
+func paginate[T any](rows []T, list listQuery, key func(T, string) sortKey) ([]T, int, string) {
+	keys := make([]sortKey, len(rows))
+	order := make([]int, len(rows))
+	for i, row := range rows {
+		keys[i] = key(row, list.field)
+		order[i] = i
+	}
+	before := func(a, b sortKey) bool {
+		if list.desc {
+			return b.less(a)
+		}
+		return a.less(b)
+	}
+	sort.Slice(order, func(i, j int) bool { return before(keys[order[i]], keys[order[j]]) })
+
+	page := []T{}
+	next := ""
+	for _, i := range order {
+		if list.after != nil && !before(*list.after, keys[i]) {
+			continue
+		}
+		if list.limit > 0 && len(page) == list.limit {
+			next = encodeCursor(listCursor{Sort: list.sort, After: key(page[len(page)-1], list.field)})
+			break
+		}
+		page = append(page, rows[i])
+	}
+	return page, len(rows), next
+}
+
+func encodeCursor(cursor listCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// writeList responds with a page of a list: as a bare array of its items on
+// the legacy routes, and with the totals otherwise.
+func writeList(w http.ResponseWriter, r *http.Request, items interface{}, page interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if isLegacyRoute(r) {
+		json.NewEncoder(w).Encode(items)
+		return
+	}
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Docker_Management/pkg/config"
+)
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// corsMethods and corsHeaders are what a browser may use across origins.
+const (
+	corsMethods       = "GET, POST, DELETE, OPTIONS"
+	corsHeaders       = "Content-Type, Authorization, X-Request-ID"
+	corsExposeHeaders = "X-Request-ID, Content-Disposition, Deprecation, Link"
+)
+
+// uploadRoutes take tarballs as the request body, limited by MaxUploadBytes
+// instead of MaxBodyBytes.
+var uploadRoutes = map[string]bool{
+	"/images/build":  true,
+	"/images/load":   true,
+	"/images/import": true,
+
+	APIPrefix + "/images/build":  true,
+	APIPrefix + "/images/load":   true,
+	APIPrefix + "/images/import": true,
+}
+
+// middleware wraps a handler with behaviour shared by every route.
+type middleware func(http.Handler) http.Handler
+
+// chain applies middlewares to handler, the first one outermost.
+func chain(handler http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// newMiddlewareChain wraps the router with the configured middleware: request
+// IDs, access logging, panic recovery, CORS, body size limits and gzip.
+func newMiddlewareChain(router http.Handler, cfg config.HTTPConfig, cors *corsPolicy) http.Handler {
+	middlewares := []middleware{withRequestID}
+	if cfg.AccessLog {
+		middlewares = append(middlewares, accessLog(slog.New(slog.NewJSONHandler(os.Stdout, nil))))
+	}
+	middlewares = append(middlewares, recoverPanics, cors.middleware, limitBodies(cfg.MaxBodyBytes, cfg.MaxUploadBytes))
+	if cfg.Gzip {
+		middlewares = append(middlewares, gzipResponses)
+	}
+	return chain(router, middlewares...)
+}
+
+// statusRecorder remembers the status and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps streaming responses working through the recorder.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades take over the connection.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	if s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+type requestIDKey struct{}
+
+// requestIDFrom returns the ID attached by withRequestID.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID keeps a well-formed X-Request-ID from the client, or
+// generates one, and returns it on the response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID accepts up to 128 letters, digits and - _ . : characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// accessLog writes one JSON line per request. The query string is left out
+// since it may carry an access token.
+func accessLog(logger *slog.Logger) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &statusRecorder{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(recorder, r)
+
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logger.Info("request",
+				slog.String("request_id", requestIDFrom(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", recorder.bytes),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("client_addr", clientAddr(r)),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
+
+// recoverPanics turns a panicking handler into a 500 response instead of a
+// dropped connection, and logs the stack.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			log.Printf("Panic serving %s %s (request %s): %v\n%s", r.Method, r.URL.Path, requestIDFrom(r.Context()), recovered, debug.Stack())
+			if recorder.status == 0 {
+				writeErrorMessage(recorder, http.StatusInternalServerError, "Internal server error")
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// corsPolicy decides which browser origins may call the API.
+type corsPolicy struct {
+	origins   map[string]bool
+	anyOrigin bool
+	maxAge    string
+}
+
+func newCORSPolicy(cfg config.HTTPConfig) *corsPolicy {
+	policy := &corsPolicy{origins: map[string]bool{}, maxAge: strconv.Itoa(cfg.CORSMaxAge)}
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			policy.anyOrigin = true
+		}
+		policy.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return policy
+}
+
+// allows reports whether a browser on origin may call the API.
+func (c *corsPolicy) allows(origin string) bool {
+	return c.anyOrigin || c.origins[origin]
+}
+
+// middleware sets the CORS headers for allowed origins and answers
+// preflight requests. A listed origin is echoed, with credentials allowed.
+// Any other origin allowed by * gets a literal * without credentials, since
+// a wildcard must never let every site send the user's credentials.
+func (c *corsPolicy) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && c.allows(origin)
+		if origin != "" {
+			w.Header().Add("Vary", "Origin")
+		}
+		if allowed {
+			if c.origins[origin] && origin != "*" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+				w.Header().Set("Access-Control-Max-Age", c.maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBodies caps request bodies at maxBody bytes, or maxUpload for the
+// tarball upload routes. A limit of 0 or less means no limit.
+func limitBodies(maxBody, maxUpload int64) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := maxBody
+			if uploadRoutes[r.URL.Path] {
+				limit = maxUpload
+			}
+			if limit > 0 && r.ContentLength > limit {
+				writeErrorMessage(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			if limit > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// gzipWriters are reused across responses.
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+// gzipResponses compresses JSON and plain text responses for clients that
+// accept gzip. Event streams, tarballs and WebSocket upgrades pass through
+// untouched.
+func gzipResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || r.Header.Get("Upgrade") != "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: w}
+		defer writer.close()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// gzipResponseWriter decides whether to compress when the response header
+// is written, based on its content type.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+
+	header := g.Header()
+	if header.Get("Content-Encoding") == "" && status != http.StatusNoContent && status != http.StatusNotModified && compressible(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		g.gz = gzipWriters.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		if g.Header().Get("Content-Type") == "" {
+			g.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gz != nil {
+		return g.gz.Write(p)
+	}
+	return g.ResponseWriter.Write(p)
+}
+
+// Flush sends the compressed data written so far.
+func (g *gzipResponseWriter) Flush() {
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	if flusher, ok := g.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (g *gzipResponseWriter) close() {
+	if g.gz == nil {
+		return
+	}
+	g.gz.Close()
+	g.gz.Reset(nil)
+	gzipWriters.Put(g.gz)
+	g.gz = nil
+}
+
+// compressible reports whether a response of contentType is worth compressing.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	// Event streams are flushed message by message and stay uncompressed
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "text/") && mediaType != "text/event-stream")
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"Docker_Management/pkg/config"
+)
+
+// serveThrough sends req through the middleware configured by cfg to a
+// handler that writes a JSON body.
+func serveThrough(cfg config.HTTPConfig, req *http.Request, body string) *httptest.ResponseRecorder {
+	return serveWith(cfg, req, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+// serveWith sends req through the middleware configured by cfg to handler.
+func serveWith(cfg config.HTTPConfig, req *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	newMiddlewareChain(handler, cfg, newCORSPolicy(cfg)).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{"listed origin", []string{"https://ui.example.com"}, "https://ui.example.com", "https://ui.example.com", "true"},
+		{"unlisted origin", []string{"https://ui.example.com"}, "https://evil.example.com", "", ""},
+		{"wildcard", []string{"*"}, "https://evil.example.com", "*", ""},
+		{"listed origin beside a wildcard", []string{"*", "https://ui.example.com"}, "https://ui.example.com", "https://ui.example.com", "true"},
+		{"wildcard beside a listed origin", []string{"*", "https://ui.example.com"}, "https://evil.example.com", "*", ""},
+	}
+	for _, test := range tests {
+		cfg := config.HTTPConfig{CORSAllowedOrigins: test.origins, CORSMaxAge: 600}
+		for _, method := range []string{http.MethodGet, http.MethodOptions} {
+			req := httptest.NewRequest(method, "/api/v1/containers", nil)
+			req.Header.Set("Origin", test.origin)
+			if method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := serveThrough(cfg, req, "{}")
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != test.allowOrigin {
+				t.Errorf("%s %s: Access-Control-Allow-Origin = %q, want %q", test.name, method, got, test.allowOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != test.credentials {
+				t.Errorf("%s %s: Access-Control-Allow-Credentials = %q, want %q", test.name, method, got, test.credentials)
+			}
+			if method == http.MethodOptions && rec.Code != http.StatusNoContent {
+				t.Errorf("%s: preflight status %d, want 204", test.name, rec.Code)
+			}
+		}
+	}
+}
+
+func TestGzipResponses(t *testing.T) {
+	body := strings.Repeat(`{"id":"aaa"}`, 100)
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		gzipped        bool
+	}{
+		{"json", "gzip, deflate", "application/json", true},
+		{"text", "gzip", "text/plain; charset=utf-8", true},
+		{"not accepted", "", "application/json", false},
+		{"event stream", "gzip", "text/event-stream", false},
+		{"tarball", "gzip", "application/x-tar", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/containers", nil)
+		req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		rec := serveWith(config.HTTPConfig{Gzip: true}, req, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", test.contentType)
+			w.Write([]byte(body))
+		})
+
+		if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != test.gzipped {
+			t.Errorf("%s: gzipped = %v, want %v", test.name, gzipped, test.gzipped)
+			continue
+		}
+		var reader io.Reader = rec.Body
+		if test.gzipped {
+			gz, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			reader = gz
+		}
+		if got, err := io.ReadAll(reader); err != nil || string(got) != body {
+			t.Errorf("%s: body %q (%v), want the handler's body", test.name, got, err)
+		}
+	}
+}
+
+func TestLimitBodies(t *testing.T) {
+	cfg := config.HTTPConfig{MaxBodyBytes: 10, MaxUploadBytes: 100}
+	readBody := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		size    int
+		chunked bool // Send without a Content-Length, so only reading finds the limit
+		status  int
+	}{
+		{"small body", "/api/v1/containers/create", 10, false, http.StatusNoContent},
+		{"large body", "/api/v1/containers/create", 11, false, http.StatusRequestEntityTooLarge},
+		{"large chunked body", "/api/v1/containers/create", 11, true, http.StatusRequestEntityTooLarge},
+		{"upload", "/api/v1/images/load", 100, false, http.StatusNoContent},
+		{"legacy upload", "/images/load", 100, true, http.StatusNoContent},
+		{"large upload", "/api/v1/images/load", 101, true, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(strings.Repeat("x", test.size)))
+		if test.chunked {
+			req.ContentLength = -1
+		}
+		if rec := serveWith(cfg, req, readBody); rec.Code != test.status {
+			t.Errorf("%s: status %d, want %d; body %s", test.name, rec.Code, test.status, rec.Body)
+		}
+	}
+}
@@ -3,122 +3,202 @@ package api
 import (
 	"encoding/json"
 	"net/http"
-	"Docker_Management/pkg/docker" // replace with the actual path to your docker package
+	"time"
+
+	"Docker_Management/pkg/docker"
+
+	"github.com/docker/docker/api/types"
 )
 
-func ListNetworksHandler(w http.ResponseWriter, r *http.Request) {
-	// Get the list of networks
-	networks, err := docker.ListNetworks()
+// NetworkResponse is a network in the network list
+type NetworkResponse struct {
+	Host    string `json:"host"` // Docker host the network belongs to
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Driver  string `json:"driver"`
+	Scope   string `json:"scope"`
+	Created string `json:"created"`
+}
+
+// NetworkPage is a page of the network list
+type NetworkPage struct {
+	Total      int               `json:"total"` // Matching networks in all pages
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Items      []NetworkResponse `json:"items"`
+}
+
+// hostNetwork is a network as listed by the daemon of host
+type hostNetwork struct {
+	host string
+	types.NetworkResource
+}
+
+func networkSortKey(network hostNetwork, field string) sortKey {
+	key := sortKey{ID: network.host + "/" + network.ID}
+	switch field {
+	case "created":
+		key.Number = network.Created.UnixNano()
+	case "name":
+		key.Text = network.Name
+	}
+	return key
+}
+
+// ListNetworksHandler lists the networks. The label, name, dangling and
+// driver query parameters filter the list on the daemon.
+func (h *Handlers) ListNetworksHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := parseListQuery(r, "created", "name")
 	if err != nil {
-		http.Error(w, "Failed to retrieve networks", http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	// Check if no networks were found
-	if len(networks) == 0 {
-		http.Error(w, "No networks found", http.StatusNotFound)
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
+	// Get the list of networks, labelling each row with its host
+	filter := listFilters(r.URL.Query(), networkFilters)
+	principal := principalFor(r)
+	var rows []hostNetwork
+	for _, target := range targets {
+		hostNetworks, err := target.docker.ListNetworks(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to retrieve networks on host "+target.name, err)
+			return
+		}
+		for _, network := range hostNetworks {
+			// Callers scoped by label only see their own networks
+			if !principal.InScope(network.Labels) {
+				continue
+			}
+			rows = append(rows, hostNetwork{host: target.name, NetworkResource: network})
+		}
+	}
+
+	page, total, next := paginate(rows, list, networkSortKey)
+	networks := []NetworkResponse{}
+	for _, network := range page {
+		networks = append(networks, NetworkResponse{
+			Host:    network.host,
+			ID:      network.ID,
+			Name:    network.Name,
+			Driver:  network.Driver,
+			Scope:   network.Scope,
+			Created: network.Created.Format(time.RFC3339),
+		})
+	}
+
 	// Send response
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	json.NewEncoder(w).Encode(networks)
+	writeList(w, r, networks, NetworkPage{Total: total, NextCursor: next, Items: networks})
 }
 
 // InspectNetworkHandler handles the request to inspect a Docker network by its ID
-func InspectNetworkHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) InspectNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	var reqBody RequestBody
 
 	// Decode the request body to get the network ID
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		http.Error(w, "Invalid input format", http.StatusBadRequest)
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Check if the ID field is empty
 	if reqBody.ID == "" {
-		http.Error(w, "Network ID is required", http.StatusBadRequest)
+		writeErrorMessage(w, http.StatusBadRequest, "Network ID is required")
 		return
 	}
 
 	// Inspect the network
-	networkDetails, err := docker.InspectNetwork(reqBody.ID)
+	networkDetails, err := dockerService.InspectNetwork(r.Context(), reqBody.ID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	// Send response in JSON format
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	json.NewEncoder(w).Encode(networkDetails)
 }
 
 type NetworkRequestBody struct {
-    NetworkID string `json:"id"`
+	NetworkID string `json:"id"`
 }
 
-func ListContainersInNetworkHandler(w http.ResponseWriter, r *http.Request) {
-    var reqBody NetworkRequestBody
-
-    // Parse the request body
-    if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-        http.Error(w, "Invalid request payload", http.StatusBadRequest)
-        return
-    }
-
-    // Call the Docker function to get containers attached to the network
-    containers, err := docker.ListContainersInNetwork(reqBody.NetworkID)
-    if err != nil {
-        http.Error(w, err.Error(), http.StatusNotFound)
-        return
-    }
-
-    // Encode the response as JSON and send it back to the client
-    w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-    json.NewEncoder(w).Encode(map[string]interface{}{
-        "containers_attached_to_network": containers,
-    })
+// NetworkContainersResponse lists the containers attached to a network
+type NetworkContainersResponse struct {
+	Containers []docker.NetworkContainer `json:"containers_attached_to_network"`
+}
+
+func (h *Handlers) ListContainersInNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var reqBody NetworkRequestBody
+
+	// Parse the request body
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Call the Docker function to get containers attached to the network
+	containers, err := dockerService.ListContainersInNetwork(r.Context(), reqBody.NetworkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// Encode the response as JSON and send it back to the client
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NetworkContainersResponse{Containers: containers})
 }
 
 type NetworkRemoveRequestBody struct {
-    NetworkID string `json:"id"`
+	NetworkID string `json:"id"`
+	ProtectionOverride
 }
 
-func RemoveNetworkHandler(w http.ResponseWriter, r *http.Request) {
-    var reqBody NetworkRemoveRequestBody
-
-    // Parse the request body
-    if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-        http.Error(w, "Invalid request payload", http.StatusBadRequest)
-        return
-    }
-
-    // Call the Docker function to remove the network
-    message, err := docker.RemoveNetwork(reqBody.NetworkID)
-    if err != nil {
-        http.Error(w, err.Error(), http.StatusNotFound)
-        return
-    }
-
-    // Send success message in response
-    w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-    json.NewEncoder(w).Encode(map[string]string{
-        "message": message,
-    })
-}
\ No newline at end of file
+func (h *Handlers) RemoveNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var reqBody NetworkRemoveRequestBody
+
+	// Parse the request body
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	protection, err := h.protectionFor(reqBody.ProtectionOverride)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Call the Docker function to remove the network
+	message, err := dockerService.RemoveNetwork(r.Context(), reqBody.NetworkID, protection)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// Send success message in response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MessageResponse{Message: message})
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// legacyRoutePrefix marks the names of the deprecated unversioned routes.
+const legacyRoutePrefix = "legacy."
+
+// pathVariable matches a mux path variable, with its optional pattern.
+var pathVariable = regexp.MustCompile(`\{(\w+)(?::[^}]*)?\}`)
+
+// hostScoped are the first path segments of routes acting on the Docker host
+// chosen by the host query parameter.
+var hostScoped = map[string]bool{
+	"containers": true,
+	"images":     true,
+	"volumes":    true,
+	"networks":   true,
+	"projects":   true,
+	"exec":       true,
+	"events":     true,
+	"prune":      true,
+}
+
+var pathVariableDescriptions = map[string]string{
+	"id":      "ID or name",
+	"ref":     "Image ID or reference, which may contain slashes",
+	"name":    "Volume name",
+	"exec":    "Exec session ID",
+	"project": "Compose project name",
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Docker Management API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "` + APIPrefix + `/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// OpenAPIHandler serves the OpenAPI document describing every route
+func (h *Handlers) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.openAPI)
+}
+
+// SwaggerUIHandler serves a Swagger UI page for the OpenAPI document
+func (h *Handlers) SwaggerUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, swaggerUIPage)
+}
+
+// documentedRoute is one method of a route registered on the router.
+type documentedRoute struct {
+	name, method, path string
+	legacy             bool
+}
+
+// buildOpenAPI describes every route of router as an OpenAPI 3 document. A
+// route without a documented operation is an error, so none can be left out.
+func buildOpenAPI(router *mux.Router) ([]byte, error) {
+	var routes []documentedRoute
+	successors := map[string]string{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			// Subrouters only group routes
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(route.GetName(), legacyRoutePrefix)
+		legacy := name != route.GetName()
+		if _, ok := operations[name]; !ok {
+			return fmt.Errorf("route %s %s has no documented operation", strings.Join(methods, ","), path)
+		}
+		for _, method := range methods {
+			routes = append(routes, documentedRoute{name: name, method: method, path: path, legacy: legacy})
+			if !legacy {
+				successors[name] = method + " " + pathVariable.ReplaceAllString(path, "{$1}")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := newSchemaBuilder()
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		path := pathVariable.ReplaceAllString(route.path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(route.method)] = describeOperation(schemas, route, successors[route.name])
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Docker Management API",
+			"version":     strings.TrimPrefix(APIPrefix, "/api/"),
+			"description": "Manage containers, images, volumes and networks on one or more Docker hosts. The unversioned routes are deprecated aliases of those under " + APIPrefix + ".",
+		},
+		"paths": paths,
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+			map[string]interface{}{"accessToken": []string{}},
+		},
+		"components": map[string]interface{}{
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "The request failed",
+					"content":     jsonContent(schemas.schemaOf(ErrorResponse{})),
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth":  map[string]interface{}{"type": "http", "scheme": "bearer"},
+				"accessToken": map[string]interface{}{"type": "apiKey", "in": "query", "name": "access_token"},
+			},
+			"schemas": schemas.components,
+		},
+	}
+	return json.Marshal(document)
+}
+
+// describeOperation documents one method of a route. A legacy route names
+// the versioned route succeeding it, and takes its path variables in the
+// query string.
+func describeOperation(schemas *schemaBuilder, route documentedRoute, successor string) map[string]interface{} {
+	op := operations[route.name]
+	if route.legacy && op.legacyResponse != nil {
+		op.response = op.legacyResponse
+	}
+
+	segment := strings.SplitN(strings.TrimPrefix(strings.TrimPrefix(route.path, APIPrefix), "/"), "/", 2)[0]
+	tag := segment
+	if route.name == "getOpenAPI" || route.name == "swaggerUI" {
+		tag = "docs"
+	}
+
+	description := op.description
+	var parameters []interface{}
+	if route.legacy {
+		if successor != "" {
+			description = strings.TrimSpace("Deprecated alias of " + successor + ". " + description)
+			parameters = legacyTargetParameters(successor, op)
+		}
+	} else {
+		for _, match := range pathVariable.FindAllStringSubmatch(route.path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        match[1],
+				"in":          "path",
+				"required":    true,
+				"description": pathVariableDescriptions[match[1]],
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	if hostScoped[segment] {
+		parameters = append(parameters, queryParameter(parameter{"host", "string", "Docker host by name, the default host when omitted; all on list routes and events"}))
+	}
+	for _, param := range op.query {
+		parameters = append(parameters, queryParameter(param))
+	}
+
+	described := map[string]interface{}{
+		"summary":   op.summary,
+		"tags":      []string{tag},
+		"responses": describeResponses(schemas, op),
+	}
+	if !route.legacy {
+		described["operationId"] = route.name
+	} else {
+		described["deprecated"] = true
+	}
+	if description != "" {
+		described["description"] = description
+	}
+	if len(parameters) > 0 {
+		described["parameters"] = parameters
+	}
+	if op.public {
+		described["security"] = []interface{}{}
+	}
+
+	content := map[string]interface{}{}
+	if op.body != nil {
+		content["application/json"] = map[string]interface{}{"schema": schemas.schemaOf(op.body)}
+	}
+	if op.upload != "" {
+		content[op.upload] = map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}
+	}
+	if len(content) > 0 {
+		described["requestBody"] = map[string]interface{}{"content": content}
+	}
+	return described
+}
+
+// legacyTargetParameters are the query parameters a legacy route takes in
+// place of the path variables of its successor, named after the request fields.
+func legacyTargetParameters(successor string, op operation) []interface{} {
+	var parameters []interface{}
+	for _, match := range pathVariable.FindAllStringSubmatch(successor, -1) {
+		name := match[1]
+		if name == "ref" {
+			name = "id"
+			if op.legacyRef != "" {
+				name = op.legacyRef
+			}
+		}
+		parameters = append(parameters, queryParameter(parameter{name, "string", pathVariableDescriptions[match[1]] + ", in the query or the JSON body"}))
+	}
+	return parameters
+}
+
+func queryParameter(param parameter) map[string]interface{} {
+	schema := map[string]interface{}{"type": param.kind}
+	if param.kind == "array" {
+		schema["items"] = map[string]interface{}{"type": "string"}
+	}
+	described := map[string]interface{}{"name": param.name, "in": "query", "schema": schema}
+	if param.description != "" {
+		described["description"] = param.description
+	}
+	return described
+}
+
+func describeResponses(schemas *schemaBuilder, op operation) map[string]interface{} {
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	content := map[string]interface{}{}
+	if op.response != nil {
+		content["application/json"] = map[string]interface{}{"schema": schemas.schemaOf(op.response)}
+	}
+	if op.produces != "" {
+		schema := map[string]interface{}{"type": "string"}
+		if op.produces == mediaTar {
+			schema["format"] = "binary"
+		}
+		content[op.produces] = map[string]interface{}{"schema": schema}
+	}
+	if len(content) > 0 {
+		success["content"] = content
+	}
+
+	return map[string]interface{}{
+		fmt.Sprint(status): success,
+		"default":          map[string]interface{}{"$ref": "#/components/responses/Error"},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
@@ -0,0 +1,450 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"Docker_Management/pkg/config"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// openAPIDocument is the part of the OpenAPI document the contract test reads.
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas   map[string]map[string]interface{} `json:"schemas"`
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+	Responses   map[string]struct {
+		Content map[string]struct {
+			Schema map[string]interface{} `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+// successStatus is the documented status of a successful call.
+func (op openAPIOperation) successStatus() int {
+	for code := range op.Responses {
+		if status, err := strconv.Atoi(code); err == nil {
+			return status
+		}
+	}
+	return 0
+}
+
+// contractCall is a request exercising one documented operation. Its path may
+// name a job started by an earlier call, as {pullJob} or {pushJob}.
+type contractCall struct {
+	operation   string
+	method      string
+	path        string
+	body        string
+	contentType string
+}
+
+// contractCalls call every versioned operation once, in an order where the
+// jobs are started before they are inspected, streamed and cancelled.
+var contractCalls = []contractCall{
+	{operation: "getOpenAPI", method: "GET", path: "/api/v1/openapi.json"},
+	{operation: "swaggerUI", method: "GET", path: "/docs"},
+	{operation: "whoAmI", method: "GET", path: "/api/v1/auth/me"},
+	{operation: "listHosts", method: "GET", path: "/api/v1/hosts"},
+	{operation: "streamEvents", method: "GET", path: "/api/v1/events?type=container"},
+	{operation: "prune", method: "POST", path: "/api/v1/prune", body: `{"dry_run":true}`},
+
+	{operation: "listContainers", method: "GET", path: "/api/v1/containers?all=true&size=true"},
+	{operation: "createContainer", method: "POST", path: "/api/v1/containers", body: `{"image":"nginx"}`},
+	{operation: "runContainer", method: "POST", path: "/api/v1/containers/run", body: `{"image":"nginx"}`},
+	{operation: "streamContainerStats", method: "GET", path: "/api/v1/containers/stats/stream?id=aaa"},
+	{operation: "inspectContainer", method: "GET", path: "/api/v1/containers/aaa"},
+	{operation: "startContainer", method: "POST", path: "/api/v1/containers/bbb/start"},
+	{operation: "stopContainer", method: "POST", path: "/api/v1/containers/aaa/stop"},
+	{operation: "restartContainer", method: "POST", path: "/api/v1/containers/aaa/restart", body: `{"timeout":1}`},
+	{operation: "pauseContainer", method: "POST", path: "/api/v1/containers/aaa/pause"},
+	{operation: "unpauseContainer", method: "POST", path: "/api/v1/containers/aaa/unpause"},
+	{operation: "killContainer", method: "POST", path: "/api/v1/containers/aaa/kill", body: `{"signal":"SIGTERM"}`},
+	{operation: "renameContainer", method: "POST", path: "/api/v1/containers/aaa/rename", body: `{"name":"front"}`},
+	{operation: "waitContainer", method: "POST", path: "/api/v1/containers/aaa/wait", body: `{"condition":"not-running"}`},
+	{operation: "createExec", method: "POST", path: "/api/v1/containers/aaa/exec", body: `{"cmd":["ls"]}`},
+	{operation: "getContainerLogs", method: "GET", path: "/api/v1/containers/aaa/logs"},
+	{operation: "streamContainerLogs", method: "GET", path: "/api/v1/containers/aaa/logs/stream?follow=false"},
+	{operation: "getContainerStats", method: "GET", path: "/api/v1/containers/aaa/stats"},
+	{operation: "exportContainer", method: "GET", path: "/api/v1/containers/aaa/export"},
+	{operation: "attachExec", method: "GET", path: "/api/v1/exec/exec1/attach"},
+	{operation: "removeContainer", method: "DELETE", path: "/api/v1/containers/bbb"},
+	{operation: "removeAllContainers", method: "DELETE", path: "/api/v1/containers"},
+
+	{operation: "listImages", method: "GET", path: "/api/v1/images"},
+	{operation: "listDanglingImages", method: "GET", path: "/api/v1/images/dangling"},
+	{operation: "imageUsage", method: "GET", path: "/api/v1/images/usage"},
+	{operation: "pullImage", method: "POST", path: "/api/v1/images/pull", body: `{"image":"nginx:latest"}`},
+	{operation: "pullImageJob", method: "POST", path: "/api/v1/images/pull/jobs", body: `{"image":"nginx:latest"}`},
+	{operation: "pushImageJob", method: "POST", path: "/api/v1/images/registry.example.com/app:1/push/jobs", body: `{}`},
+	{operation: "mirrorImageJob", method: "POST", path: "/api/v1/images/mirror/jobs", body: `{"source":"a.example.com/app:1","target":"b.example.com/app:1"}`},
+	{operation: "buildImage", method: "POST", path: "/api/v1/images/build", body: `{"git_url":"https://example.com/app.git","tags":["app:1"]}`},
+	{operation: "saveImages", method: "GET", path: "/api/v1/images/save?image=nginx:latest"},
+	{operation: "loadImages", method: "POST", path: "/api/v1/images/load", body: "tar", contentType: mediaTar},
+	{operation: "importImage", method: "POST", path: "/api/v1/images/import?reference=app:1", body: "tar", contentType: mediaTar},
+	{operation: "inspectImage", method: "GET", path: "/api/v1/images/nginx:latest/json"},
+	{operation: "imageHistory", method: "GET", path: "/api/v1/images/nginx:latest/history"},
+	{operation: "tagImage", method: "POST", path: "/api/v1/images/nginx:latest/tag", body: `{"target":"registry.example.com/nginx:1"}`},
+	{operation: "untagImage", method: "DELETE", path: "/api/v1/images/registry.example.com/nginx:1/tag"},
+	{operation: "removeImage", method: "DELETE", path: "/api/v1/images/nginx:latest"},
+	{operation: "removeDanglingImages", method: "DELETE", path: "/api/v1/images/dangling"},
+	{operation: "removeAllImages", method: "DELETE", path: "/api/v1/images"},
+
+	{operation: "listJobs", method: "GET", path: "/api/v1/jobs"},
+	{operation: "getJob", method: "GET", path: "/api/v1/jobs/{pullJob}"},
+	{operation: "streamJob", method: "GET", path: "/api/v1/jobs/{pullJob}/stream"},
+	{operation: "cancelJob", method: "POST", path: "/api/v1/jobs/{pushJob}/cancel"},
+
+	{operation: "listVolumes", method: "GET", path: "/api/v1/volumes"},
+	{operation: "inspectVolume", method: "GET", path: "/api/v1/volumes/data"},
+	{operation: "listVolumeContainers", method: "GET", path: "/api/v1/volumes/data/containers"},
+	{operation: "removeVolume", method: "DELETE", path: "/api/v1/volumes/data"},
+
+	{operation: "listNetworks", method: "GET", path: "/api/v1/networks"},
+	{operation: "inspectNetwork", method: "GET", path: "/api/v1/networks/net1"},
+	{operation: "listNetworkContainers", method: "GET", path: "/api/v1/networks/net1/containers"},
+	{operation: "removeNetwork", method: "DELETE", path: "/api/v1/networks/net1"},
+
+	{operation: "listProjects", method: "GET", path: "/api/v1/projects"},
+	{operation: "inspectProject", method: "GET", path: "/api/v1/projects/shop"},
+	{operation: "streamProjectLogs", method: "GET", path: "/api/v1/projects/shop/logs/stream?follow=false"},
+	{operation: "startProject", method: "POST", path: "/api/v1/projects/shop/start"},
+	{operation: "stopProject", method: "POST", path: "/api/v1/projects/shop/stop", body: `{"timeout":1}`},
+	{operation: "restartProject", method: "POST", path: "/api/v1/projects/shop/restart"},
+	{operation: "downProject", method: "POST", path: "/api/v1/projects/shop/down", body: `{"volumes":true}`},
+
+	// Run last, so the log holds the calls above
+	{operation: "listHistory", method: "GET", path: "/api/v1/history"},
+	{operation: "listAudit", method: "GET", path: "/api/v1/audit"},
+}
+
+// contractErrorCalls fail, and must answer with the documented error response.
+var contractErrorCalls = []struct {
+	contractCall
+	status int
+}{
+	{contractCall{method: "GET", path: "/api/v1/containers/missing"}, http.StatusNotFound},
+	{contractCall{method: "POST", path: "/api/v1/projects/nope/start"}, http.StatusNotFound},
+	{contractCall{method: "GET", path: "/api/v1/containers?sort=color"}, http.StatusBadRequest},
+	{contractCall{method: "POST", path: "/api/v1/images/pull", body: `{}`}, http.StatusBadRequest},
+	{contractCall{method: "GET", path: "/api/v1/jobs/missing"}, http.StatusNotFound},
+}
+
+// TestOpenAPIContract calls every operation of the OpenAPI document against a
+// stub daemon, and checks the status and body of each response against it.
+func TestOpenAPIContract(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPI(t, newStubService(), config.AuthConfig{
+		Users: []config.AuthUser{{Username: "admin", PasswordHash: string(hash), Role: "admin"}},
+	})
+	server := httptest.NewServer(api.router)
+	defer server.Close()
+
+	// The document is public
+	resp, err := http.Get(server.URL + "/api/v1/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc openAPIDocument
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || err != nil {
+		t.Fatalf("GET /api/v1/openapi.json: status %d, %v", resp.StatusCode, err)
+	}
+
+	byID := map[string]openAPIOperation{}
+	for _, methods := range doc.Paths {
+		for _, op := range methods {
+			if op.OperationID != "" {
+				byID[op.OperationID] = op
+			}
+		}
+	}
+
+	// Log in, which also exercises the login operation
+	login := contractCall{operation: "login", method: "POST", path: "/api/v1/auth/login", body: `{"username":"admin","password":"secret"}`}
+	var loginResponse LoginResponse
+	body := checkContractCall(t, server, &doc, byID, login, "")
+	if err := json.Unmarshal(body, &loginResponse); err != nil || loginResponse.Token == "" {
+		t.Fatalf("login: no token in %s", body)
+	}
+	token := loginResponse.Token
+
+	called := map[string]bool{"login": true}
+	jobs := map[string]string{}
+	for _, call := range contractCalls {
+		for name, id := range jobs {
+			call.path = strings.ReplaceAll(call.path, "{"+name+"}", id)
+		}
+		body := checkContractCall(t, server, &doc, byID, call, token)
+		called[call.operation] = true
+
+		if call.operation == "pullImageJob" || call.operation == "pushImageJob" {
+			var job struct {
+				ID string `json:"id"`
+			}
+			json.Unmarshal(body, &job)
+			jobs[strings.TrimSuffix(call.operation, "ImageJob")+"Job"] = job.ID
+		}
+		if call.operation == "pullImageJob" {
+			// Let the pull finish, so its stream ends
+			waitForJob(t, api, jobs["pullJob"])
+		}
+	}
+
+	var missing []string
+	for id := range byID {
+		if !called[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("operations not exercised: %s", strings.Join(missing, ", "))
+	}
+
+	errorSchema := doc.Components.Responses["Error"].Content["application/json"].Schema
+	for _, call := range contractErrorCalls {
+		resp := doContractRequest(t, server, call.contractCall, token)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != call.status {
+			t.Errorf("%s %s: status %d, want %d; body %s", call.method, call.path, resp.StatusCode, call.status, body)
+			continue
+		}
+		checkJSONBody(t, &doc, errorSchema, call.method+" "+call.path, body)
+	}
+}
+
+// TestOpenAPILegacyDocument checks that the unversioned document is the same.
+func TestOpenAPILegacyDocument(t *testing.T) {
+	api := newTestAPI(t, newStubService(), config.AuthConfig{})
+
+	versioned := api.do(http.MethodGet, "/api/v1/openapi.json", "")
+	legacy := api.do(http.MethodGet, "/openapi.json", "")
+	if legacy.Code != http.StatusOK || legacy.Body.String() != versioned.Body.String() {
+		t.Errorf("GET /openapi.json: status %d, differs from the versioned document", legacy.Code)
+	}
+}
+
+func waitForJob(t *testing.T, api *testAPI, id string) {
+	t.Helper()
+	job, err := api.jobs.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ignore := func(jsonmessage.JSONMessage) error { return nil }
+	if err := job.Follow(ctx, ignore); err != nil {
+		t.Fatalf("job %s did not finish: %v", id, err)
+	}
+}
+
+func doContractRequest(t *testing.T, server *httptest.Server, call contractCall, token string) *http.Response {
+	t.Helper()
+	var body io.Reader
+	if call.body != "" {
+		body = strings.NewReader(call.body)
+	}
+	req, err := http.NewRequest(call.method, server.URL+call.path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.body != "" {
+		contentType := call.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	// Streams are only read up to their headers, then closed
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("%s %s: %v", call.method, call.path, err)
+	}
+	return resp
+}
+
+// checkContractCall makes call and checks its response against the
+// documented operation, returning the response body.
+func checkContractCall(t *testing.T, server *httptest.Server, doc *openAPIDocument, byID map[string]openAPIOperation, call contractCall, token string) []byte {
+	t.Helper()
+	op, ok := byID[call.operation]
+	if !ok {
+		t.Errorf("%s is not documented", call.operation)
+		return nil
+	}
+	want := op.successStatus()
+
+	if call.operation == "attachExec" {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + call.path + "?access_token=" + token
+		conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Errorf("%s: %v", call.operation, err)
+			return nil
+		}
+		defer conn.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: status %d, want %d", call.operation, resp.StatusCode, want)
+		}
+		for {
+			var message ExecMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				t.Errorf("%s: no exit message: %v", call.operation, err)
+				return nil
+			}
+			if message.Type == execMessageExit {
+				return nil
+			}
+		}
+	}
+
+	resp := doContractRequest(t, server, call, token)
+	defer resp.Body.Close()
+	mediaType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if mediaType == mediaEventStream {
+		if resp.StatusCode != want {
+			t.Errorf("%s: status %d, want %d", call.operation, resp.StatusCode, want)
+		}
+		if _, documented := op.Responses[strconv.Itoa(want)].Content[mediaEventStream]; !documented {
+			t.Errorf("%s: undocumented event stream", call.operation)
+		}
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s: %v", call.operation, err)
+	}
+	if resp.StatusCode != want {
+		t.Errorf("%s: status %d, want %d; body %s", call.operation, resp.StatusCode, want, body)
+		return body
+	}
+
+	content, documented := op.Responses[strconv.Itoa(want)].Content[mediaType]
+	if !documented {
+		t.Errorf("%s: undocumented response type %q", call.operation, mediaType)
+		return body
+	}
+	if mediaType == "application/json" {
+		checkJSONBody(t, doc, content.Schema, call.operation, body)
+	}
+	return body
+}
+
+func checkJSONBody(t *testing.T, doc *openAPIDocument, schema map[string]interface{}, name string, body []byte) {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Errorf("%s: invalid JSON: %v", name, err)
+		return
+	}
+	for _, problem := range checkSchema(doc, schema, value, "$") {
+		t.Errorf("%s: %s", name, problem)
+	}
+}
+
+// checkSchema lists where value does not match schema. Null matches any
+// schema, since encoding/json writes nil slices, maps and pointers as null,
+// and an object may only hold the properties its schema names.
+func checkSchema(doc *openAPIDocument, schema map[string]interface{}, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := doc.Components.Schemas[name]
+		if !ok {
+			return []string{at + ": unknown schema " + ref}
+		}
+		schema = resolved
+	}
+	if value == nil {
+		return nil
+	}
+
+	mismatch := func() []string {
+		return []string{fmt.Sprintf("%s: %v is not of type %v", at, value, schema["type"])}
+	}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		var problems []string
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for key, field := range object {
+			fieldSchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				fieldSchema = additional
+			}
+			if fieldSchema == nil {
+				problems = append(problems, at+": undocumented property "+key)
+				continue
+			}
+			problems = append(problems, checkSchema(doc, fieldSchema, field, at+"."+key)...)
+		}
+		return problems
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		var problems []string
+		for i, item := range array {
+			problems = append(problems, checkSchema(doc, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch()
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != math.Trunc(number) {
+			return mismatch()
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"net/http"
+
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/docker"
+
+	"github.com/docker/docker/api/types"
+)
+
+// operation documents a route in the OpenAPI document. Bodies are given as
+// values of the types the handler decodes and encodes, so the document
+// follows the handlers as they change.
+type operation struct {
+	summary     string
+	description string
+	public      bool // Served without authentication
+	query       []parameter
+	body        interface{} // JSON request body
+	upload      string      // Media type of a raw request body
+	status      int         // Success status; 200 when zero
+	response    interface{} // JSON response body
+	produces    string      // Media type of a non-JSON response
+	legacyRef   string      // Request field naming the image on the legacy route, when not id
+
+	legacyResponse interface{} // JSON response body of the legacy route, when it differs
+}
+
+// parameter is a query parameter. Its kind is string, boolean, integer or
+// array, a repeatable string.
+type parameter struct {
+	name, kind, description string
+}
+
+const (
+	mediaTar         = "application/x-tar"
+	mediaEventStream = "text/event-stream"
+)
+
+var (
+	logPageParams = []parameter{
+		{"since", "string", "Earliest time, RFC 3339"},
+		{"until", "string", "Latest time, RFC 3339"},
+		{"offset", "integer", "Entries to skip"},
+		{"limit", "integer", "Page size, default 50, at most 500"},
+	}
+	listPageParams = []parameter{
+		{"cursor", "string", "next_cursor of the previous page"},
+		{"limit", "integer", "Page size, default 100, at most 1000"},
+	}
+	overrideParams = []parameter{
+		{"override", "boolean", "Remove the resource even if it is protected"},
+		{"reason", "string", "Why protection is overridden; required with override"},
+	}
+)
+
+// containerFilterParams are the filters of the container lists, and their size option.
+var containerFilterParams = []parameter{
+	{"size", "boolean", "Report the disk usage of each container, which is slow"},
+	{"status", "array", "State, e.g. running, exited or paused"},
+	{"label", "array", "Label, key or key=value"},
+	{"name", "array", "Name, or part of one"},
+	{"ancestor", "array", "Image the container was created from, or one of its descendants"},
+}
+
+// resourceFilterParams are the filters of the volume and network lists.
+func resourceFilterParams(kind string) []parameter {
+	return []parameter{
+		{"label", "array", "Label, key or key=value"},
+		{"name", "array", "Name, or part of one"},
+		{"dangling", "boolean", "Only " + kind + " no container uses, or only used ones when false"},
+		{"driver", "array", "Driver"},
+	}
+}
+
+// listParams are the query parameters of a list route: its daemon filters,
+// which may repeat, the orders it can be sorted in and its paging.
+func listParams(filters []parameter, sortable string) []parameter {
+	params := append([]parameter{}, filters...)
+	params = append(params, parameter{"sort", "string", sortable + "; prefix with - to descend, default -created"})
+	return append(params, listPageParams...)
+}
+
+// operations documents every route by the name it is registered under.
+// Legacy routes share the operation of their versioned equivalent.
+var operations = map[string]operation{
+	"getOpenAPI": {summary: "This OpenAPI document", public: true, response: map[string]interface{}{}},
+	"swaggerUI":  {summary: "Interactive documentation of the API", public: true, produces: "text/html"},
+
+	"login":  {summary: "Exchange a username and password for a login token", public: true, body: LoginRequest{}, response: LoginResponse{}},
+	"whoAmI": {summary: "The caller's identity and role", response: auth.Principal{}},
+
+	"listHosts": {summary: "List the registered Docker hosts", response: []docker.HostInfo{}},
+	"listHistory": {
+		summary: "Page through the lifecycle history, newest first",
+		query: append([]parameter{
+			{"action", "string", "Action, e.g. start"},
+			{"container", "string", "Container ID"},
+			{"image", "string", "Image reference"},
+			{"actor", "string", "User, token or client address"},
+			{"source", "string", "api or daemon"},
+			{"outcome", "string", "success or failure"},
+		}, logPageParams...),
+		response: HistoryResponse{},
+	},
+	"listAudit": {
+		summary: "Page through the audit log, newest first",
+		query: append([]parameter{
+			{"principal", "string", "User or API token"},
+			{"method", "string", "HTTP method"},
+			{"route", "string", "Route template"},
+			{"target", "string", "Resource ID or name; prefixes match"},
+			{"outcome", "string", "success or failure"},
+		}, logPageParams...),
+		response: AuditResponse{},
+	},
+	"streamEvents": {
+		summary:     "Stream daemon events",
+		description: "Server-Sent Events named after the event type (container, image, volume or network), each holding a docker Event.",
+		query: []parameter{
+			{"type", "array", "Event types to follow"},
+			{"action", "array", "Event actions to follow"},
+			{"label", "array", "Labels, key or key=value"},
+		},
+		produces: mediaEventStream,
+	},
+	"prune": {
+		summary:     "Remove unused containers, images, volumes, networks and build cache",
+		description: "With dry_run set nothing is removed and the report lists what would be. Build cache has no labels: with label filters, naming build_cache is refused, and pruning every type leaves the build cache alone and says so in not_pruned.",
+		body:        docker.PruneOptions{},
+		response:    docker.PruneReport{},
+	},
+
+	"listJobs":  {summary: "List the background jobs, newest first", response: []docker.JobInfo{}},
+	"getJob":    {summary: "The state of a job", response: docker.JobInfo{}},
+	"cancelJob": {summary: "Cancel a running job", status: http.StatusAccepted, response: docker.JobInfo{}},
+	"streamJob": {
+		summary:     "Stream the progress of a job",
+		description: "Server-Sent Events: \"progress\" events hold docker JSON messages, replayed from the start, and an \"end\" event holds the final JobInfo.",
+		produces:    mediaEventStream,
+	},
+
+	"listContainers": {
+		summary:        "List containers",
+		query:          append([]parameter{{"all", "boolean", "Include stopped containers"}}, listParams(containerFilterParams, "created, size or name")...),
+		response:       ContainerPage{},
+		legacyResponse: []ContainerResponse{},
+	},
+	"listAllContainers": {
+		summary:        "List every container, including stopped ones",
+		query:          listParams(containerFilterParams, "created, size or name"),
+		response:       ContainerPage{},
+		legacyResponse: []ContainerResponse{},
+	},
+	"createContainer":     {summary: "Create a container, pulling its image if it is missing", body: CreateContainerRequest{}, status: http.StatusCreated, response: CreateContainerResponse{}},
+	"runContainer":        {summary: "Create and start a container", body: CreateContainerRequest{}, status: http.StatusCreated, response: CreateContainerResponse{}},
+	"removeAllContainers": {summary: "Remove every unprotected container", response: BulkRemoveResponse{}},
+	"inspectContainer":    {summary: "Inspect a container", response: InspectResponse{}},
+	"removeContainer":     {summary: "Remove a container", query: overrideParams, response: MessageResponse{}},
+	"startContainer":      {summary: "Start a container", response: docker.ActionResult{}},
+	"stopContainer":       {summary: "Stop a container", response: docker.ActionResult{}},
+	"restartContainer":    {summary: "Restart a container", body: ContainerActionRequest{}, response: docker.ActionResult{}},
+	"pauseContainer":      {summary: "Pause a container", response: docker.ActionResult{}},
+	"unpauseContainer":    {summary: "Unpause a container", response: docker.ActionResult{}},
+	"killContainer":       {summary: "Send a signal to a container", body: ContainerActionRequest{}, response: docker.ActionResult{}},
+	"renameContainer":     {summary: "Rename a container", body: ContainerActionRequest{}, response: docker.ActionResult{}},
+	"waitContainer":       {summary: "Wait for a container to reach a condition", body: ContainerActionRequest{}, response: docker.ActionResult{}},
+	"getContainerLogs":    {summary: "The logs of a container", response: LogResponse{}},
+	"getContainerStats":   {summary: "CPU and memory usage of a container", response: StatsResponse{}},
+	"streamContainerLogs": {
+		summary:     "Stream the logs of a container",
+		description: "Server-Sent Events: \"stdout\" and \"stderr\" events hold one line each, and the stream ends with an \"end\" or \"error\" event.",
+		query: []parameter{
+			{"since", "string", "Timestamp or relative time, e.g. 10m"},
+			{"until", "string", "Timestamp or relative time"},
+			{"tail", "string", "Number of lines from the end, or all"},
+			{"timestamps", "boolean", "Prefix each line with its timestamp"},
+			{"follow", "boolean", "Keep streaming new lines; default true"},
+		},
+		produces: mediaEventStream,
+	},
+	"streamContainerStats": {
+		summary:     "Stream container resource usage",
+		description: "A \"stats\" Server-Sent Event every interval holds an array of docker StatsSample, one per running container. Stopped containers drop out; without id, containers started later join in, and with id the stream ends once they have all stopped.",
+		query: []parameter{
+			{"id", "array", "Containers to sample; every running container when omitted"},
+			{"interval", "integer", "Seconds between samples, default 5"},
+		},
+		produces: mediaEventStream,
+	},
+	"exportContainer": {summary: "Download the filesystem of a container as a tar archive", produces: mediaTar},
+	"createExec":      {summary: "Prepare a command to run in a container", body: CreateExecRequest{}, status: http.StatusCreated, response: CreateExecResponse{}},
+	"attachExec": {
+		summary:     "Run a prepared command over a WebSocket",
+		description: "Upgrades to a WebSocket carrying JSON ExecMessage frames: stdin, close_stdin and resize from the client; stdout, stderr, exit and error from the server. Output that is not valid UTF-8 arrives base64-encoded, with encoding set to base64. An exit frame without exit_code means the command outlived its output, so its exit code is unknown.",
+		status:      http.StatusSwitchingProtocols,
+	},
+
+	"listImages": {
+		summary: "List images",
+		query: listParams([]parameter{
+			{"label", "array", "Label, key or key=value"},
+			{"dangling", "boolean", "Only untagged images, or only tagged ones when false"},
+			{"name", "array", "Reference, which may contain wildcards, e.g. nginx:*"},
+		}, "created, size or name"),
+		response:       ImagePage{},
+		legacyResponse: []ImageResponse{},
+	},
+	"listDanglingImages": {
+		summary:        "List untagged images",
+		query:          listParams([]parameter{{"label", "array", "Label, key or key=value"}}, "created, size or name"),
+		response:       DanglingImagePage{},
+		legacyResponse: []DanglingImageResponse{},
+	},
+	"removeAllImages":      {summary: "Remove every unprotected image", response: BulkRemoveResponse{}},
+	"removeDanglingImages": {summary: "Remove every unprotected untagged image", response: BulkRemoveResponse{}},
+	"imageUsage":           {summary: "Disk usage of images, split into shared and unique sizes, and the base images they share", response: docker.ImageUsageReport{}},
+	"inspectImage":         {summary: "Inspect an image", response: types.ImageInspect{}},
+	"imageHistory":         {summary: "The layers of an image and the commands that created them", response: []docker.ImageLayer{}},
+	"removeImage":          {summary: "Remove an image", query: overrideParams, response: MessageResponse{}},
+	"tagImage":             {summary: "Add a reference to an image", body: TagImageRequest{}, response: MessageResponse{}, legacyRef: "source"},
+	"untagImage":           {summary: "Remove a reference from an image without deleting it", response: MessageResponse{}, legacyRef: "image"},
+	"pullImage":            {summary: "Pull an image and wait for it", body: PullImageRequest{}, response: MessageResponse{}},
+	"pullImageJob":         {summary: "Pull an image in the background", body: PullImageRequest{}, status: http.StatusAccepted, response: docker.JobInfo{}},
+	"pushImageJob":         {summary: "Push an image in the background", body: RegistryCredentials{}, status: http.StatusAccepted, response: docker.JobInfo{}, legacyRef: "image"},
+	"mirrorImageJob": {
+		summary:     "Copy an image between registries in the background",
+		description: "Once the push succeeds, the pulled source and the target tag are removed from the host again, unless keep is set or they were there before.",
+		body:        MirrorImageRequest{},
+		status:      http.StatusAccepted,
+		response:    docker.JobInfo{},
+	},
+	"buildImage": {
+		summary:     "Build an image in the background",
+		description: "The body is either a JSON BuildSpec naming a git_url or a context_path under the configured build root, or a tar (optionally gzipped) build context with the options as query parameters.",
+		query: []parameter{
+			{"dockerfile", "string", "Path of the Dockerfile in the context"},
+			{"target", "string", "Build stage"},
+			{"tag", "array", "References for the built image"},
+			{"buildarg", "array", "Build arguments, KEY=value"},
+			{"label", "array", "Labels, KEY=value"},
+			{"nocache", "boolean", "Do not use the build cache"},
+			{"pull", "boolean", "Always pull base images"},
+		},
+		body:     docker.BuildSpec{},
+		upload:   mediaTar,
+		status:   http.StatusAccepted,
+		response: docker.JobInfo{},
+	},
+	"saveImages": {
+		summary:  "Download images as a docker-save tarball",
+		query:    []parameter{{"image", "array", "Images to save"}},
+		produces: mediaTar,
+	},
+	"loadImages": {summary: "Load images from a docker-save tarball", upload: mediaTar, response: LoadImagesResponse{}},
+	"importImage": {
+		summary: "Create an image from a filesystem tarball",
+		query: []parameter{
+			{"reference", "string", "repository[:tag] of the new image"},
+			{"message", "string", "Commit message"},
+			{"change", "array", "Dockerfile instructions to apply"},
+		},
+		upload:   mediaTar,
+		response: ImportImageResponse{},
+	},
+
+	"listVolumes": {
+		summary:        "List volumes",
+		query:          listParams(resourceFilterParams("volumes"), "created or name"),
+		response:       VolumePage{},
+		legacyResponse: []VolumeResponse{},
+	},
+	"inspectVolume":        {summary: "Inspect a volume", response: types.Volume{}},
+	"listVolumeContainers": {summary: "List the containers using a volume", response: ResponseVolumeContainers{}},
+	"removeVolume":         {summary: "Remove a volume", query: overrideParams, response: MessageResponse{}},
+
+	"listNetworks": {
+		summary:        "List networks",
+		query:          listParams(resourceFilterParams("networks"), "created or name"),
+		response:       NetworkPage{},
+		legacyResponse: []NetworkResponse{},
+	},
+	"inspectNetwork":        {summary: "Inspect a network", response: map[string]interface{}{}},
+	"listNetworkContainers": {summary: "List the containers attached to a network", response: NetworkContainersResponse{}},
+	"removeNetwork":         {summary: "Remove a network", query: overrideParams, response: MessageResponse{}},
+
+	"listProjects":   {summary: "List the Docker Compose projects, with the status of their services", response: []ProjectResponse{}},
+	"inspectProject": {summary: "The services, networks and volumes of a Compose project", response: ProjectResponse{}},
+	"streamProjectLogs": {
+		summary:     "Stream the logs of every container of a Compose project",
+		description: "Server-Sent Events: \"stdout\" and \"stderr\" events hold one line each as a JSON ProjectLogLine naming its service and container. Lines of different containers are interleaved as they arrive, and the stream ends with an \"end\" event.",
+		query: []parameter{
+			{"service", "array", "Services to include; default every service"},
+			{"since", "string", "Timestamp or relative time, e.g. 10m"},
+			{"until", "string", "Timestamp or relative time"},
+			{"tail", "string", "Number of lines from the end of each log, or all"},
+			{"timestamps", "boolean", "Prefix each line with its timestamp"},
+			{"follow", "boolean", "Keep streaming new lines; default true"},
+		},
+		produces: mediaEventStream,
+	},
+	"startProject":   {summary: "Start the containers of a Compose project, dependencies first", body: ProjectActionRequest{}, response: docker.ProjectActionResult{}},
+	"stopProject":    {summary: "Stop the containers of a Compose project, dependents first", body: ProjectActionRequest{}, response: docker.ProjectActionResult{}},
+	"restartProject": {summary: "Restart the containers of a Compose project, dependencies first", body: ProjectActionRequest{}, response: docker.ProjectActionResult{}},
+	"downProject": {
+		summary:     "Stop and remove the containers and networks of a Compose project",
+		description: "Volumes are removed too when volumes is true. Protected resources are kept unless override is set, and protected containers are not stopped either.",
+		body:        ProjectActionRequest{},
+		response:    docker.ProjectActionResult{},
+	},
+}
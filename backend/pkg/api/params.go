@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIPrefix is where the versioned routes are served.
+const APIPrefix = "/api/v1"
+
+// pathAliases are path variables that fill a differently named request field:
+// an image {ref} is the id, image or source of the request, whichever it has.
+var pathAliases = map[string][]string{
+	"ref": {"id", "image", "source"},
+}
+
+// decodeRequest fills v, a pointer to a request struct, from the JSON body,
+// then from query parameters and finally from path variables, matching each
+// to the field with the same JSON name. An empty body is allowed, so that
+// DELETE and GET routes can take everything from the URL. A field the URL
+// gives a different value than the body is an error, since the request
+// would act on something other than what its body names.
+func decodeRequest(r *http.Request, v interface{}) error {
+	if r.Body != nil && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	fields := map[string]reflect.Value{}
+	collectFields(reflect.ValueOf(v).Elem(), fields)
+
+	for name, values := range r.URL.Query() {
+		if field, ok := fields[name]; ok {
+			if err := overrideField(field, name, values); err != nil {
+				return err
+			}
+		}
+	}
+	for name, value := range mux.Vars(r) {
+		for _, fieldName := range append([]string{name}, pathAliases[name]...) {
+			if field, ok := fields[fieldName]; ok {
+				if err := overrideField(field, fieldName, []string{value}); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// overrideField sets a field from URL values, unless it already holds a
+// different value.
+func overrideField(field reflect.Value, name string, values []string) error {
+	previous := field.Interface()
+	if err := setField(field, values); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	if !reflect.ValueOf(previous).IsZero() && !reflect.DeepEqual(previous, field.Interface()) {
+		return fmt.Errorf("%s is given different values in the body and the URL", name)
+	}
+	return nil
+}
+
+// collectFields indexes the settable fields of a struct by JSON name,
+// including those of embedded structs.
+func collectFields(value reflect.Value, fields map[string]reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(value.Field(i), fields)
+			continue
+		}
+		if field.IsExported() && name != "" && name != "-" {
+			fields[name] = value.Field(i)
+		}
+	}
+}
+
+// setField parses URL values into a string, bool, integer or string slice
+// field, or a pointer to one. Fields of other types are left alone.
+func setField(field reflect.Value, values []string) error {
+	switch {
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(values).Convert(field.Type()))
+	case field.Kind() == reflect.Ptr && isScalar(field.Type().Elem().Kind()):
+		target := reflect.New(field.Type().Elem())
+		if err := setScalar(target.Elem(), values[0]); err != nil {
+			return err
+		}
+		field.Set(target)
+	case isScalar(field.Kind()):
+		return setScalar(field, values[0])
+	}
+	return nil
+}
+
+func isScalar(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Bool || kind == reflect.Int || kind == reflect.Int64
+}
+
+func setScalar(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	}
+	return nil
+}
+
+// pathParam returns a path variable of the route, or the query parameter of
+// the same name on the legacy routes.
+func pathParam(r *http.Request, name string) string {
+	vars := mux.Vars(r)
+	if value, ok := vars[name]; ok {
+		return value
+	}
+	for alias, fieldNames := range pathAliases {
+		for _, fieldName := range fieldNames {
+			if fieldName == name && vars[alias] != "" {
+				return vars[alias]
+			}
+		}
+	}
+	return r.URL.Query().Get(name)
+}
+
+// deprecated marks a legacy route, superseded by the routes under APIPrefix.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+APIPrefix+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
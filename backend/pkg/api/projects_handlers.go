@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"Docker_Management/pkg/docker"
+)
+
+// ProjectResponse is a Docker Compose project and the host it runs on
+type ProjectResponse struct {
+	Host string `json:"host"`
+	docker.Project
+}
+
+// ProjectActionRequest selects a Compose project and carries the options of
+// the lifecycle action applied to it.
+type ProjectActionRequest struct {
+	Project string `json:"project"`
+	Timeout *int   `json:"timeout,omitempty"` // Seconds to wait for each container to exit before killing it
+	Volumes bool   `json:"volumes,omitempty"` // Also remove the project's volumes, for down
+	ProtectionOverride
+}
+
+func (req ProjectActionRequest) options(protection *docker.ProtectionPolicy) docker.ProjectActionOptions {
+	options := docker.ProjectActionOptions{RemoveVolumes: req.Volumes, Protection: protection}
+	if req.Timeout != nil {
+		timeout := time.Duration(*req.Timeout) * time.Second
+		options.Timeout = &timeout
+	}
+	return options
+}
+
+// ProjectLogLine is a log line of one of the containers of a project
+type ProjectLogLine struct {
+	Service   string `json:"service"`
+	Container string `json:"container"` // Container name
+	Line      string `json:"line"`
+}
+
+// ListProjectsHandler lists the Docker Compose projects, grouping containers,
+// networks and volumes by their compose project and service labels
+func (h *Handlers) ListProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Callers scoped by label only see their own containers
+	principal := principalFor(r)
+	response := []ProjectResponse{}
+	for _, target := range targets {
+		projects, err := target.docker.ListProjects(r.Context(), principal.InScope)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to list projects on host "+target.name, err)
+			return
+		}
+		for _, project := range projects {
+			response = append(response, ProjectResponse{Host: target.name, Project: project})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// InspectProjectHandler returns the status of a project and of its services
+func (h *Handlers) InspectProjectHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	project, err := dockerService.InspectProject(r.Context(), pathParam(r, "project"), principalFor(r).InScope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to inspect project", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ProjectResponse{Host: h.hostName(r), Project: project})
+}
+
+// StreamProjectLogsHandler streams the logs of every container of a project
+// as Server-Sent Events, interleaved as they arrive. Each "stdout" and
+// "stderr" event carries a ProjectLogLine naming its service and container.
+// Query parameters: service (repeatable, default every service), since,
+// until, tail, timestamps and follow (default true).
+func (h *Handlers) StreamProjectLogsHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := r.URL.Query()
+	options := docker.LogStreamOptions{
+		Since:      query.Get("since"),
+		Until:      query.Get("until"),
+		Tail:       query.Get("tail"),
+		Timestamps: query.Get("timestamps") == "true",
+		Follow:     query.Get("follow") != "false",
+	}
+
+	// Report a missing project as a plain error before the event stream starts
+	project, err := dockerService.InspectProject(r.Context(), pathParam(r, "project"), principalFor(r).InScope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to retrieve logs", err)
+		return
+	}
+	selected := map[string]bool{}
+	for _, service := range query["service"] {
+		selected[service] = true
+	}
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// Follow every container at once; the request context ends them all
+	// when the client disconnects
+	var wg sync.WaitGroup
+	for _, service := range project.Services {
+		if len(selected) > 0 && !selected[service.Name] {
+			continue
+		}
+		for _, container := range service.Containers {
+			wg.Add(1)
+			go func(service string, container docker.ProjectContainer) {
+				defer wg.Done()
+				format := func(line string) interface{} {
+					return ProjectLogLine{Service: service, Container: container.Name, Line: line}
+				}
+				stdout := &sseLineWriter{stream: stream, event: "stdout", format: format}
+				stderr := &sseLineWriter{stream: stream, event: "stderr", format: format}
+
+				err := dockerService.StreamContainerLogs(r.Context(), container.ID, options, stdout, stderr)
+				stdout.Flush()
+				stderr.Flush()
+				if err != nil {
+					stream.SendJSON("error", format(err.Error()))
+				}
+			}(service.Name, container)
+		}
+	}
+	wg.Wait()
+	stream.Send("end", "")
+}
+
+// projectAction is the body of the project lifecycle handlers. It decodes the
+// request, applies action, records the change of every container in the
+// history and responds with the steps taken. Steps that fail do not stop the
+// others, and are counted in the failed field of the response.
+func (h *Handlers) projectAction(w http.ResponseWriter, r *http.Request, action string, apply func(context.Context, docker.Service, ProjectActionRequest, *docker.ProtectionPolicy) (docker.ProjectActionResult, error)) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var requestBody ProjectActionRequest
+
+	// Decode the request from the body and URL
+	if err := decodeRequest(r, &requestBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if requestBody.Project == "" {
+		writeErrorMessage(w, http.StatusBadRequest, "Project name is required")
+		return
+	}
+	protection, err := h.protectionFor(requestBody.ProtectionOverride)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	result, err := apply(r.Context(), dockerService, requestBody, protection)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to "+action+" project", err)
+		return
+	}
+	h.saveProjectHistory(r, result, requestBody.ProtectionOverride)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// saveProjectHistory records the containers a project action started,
+// stopped, restarted or removed. Skipped containers were left untouched.
+func (h *Handlers) saveProjectHistory(r *http.Request, result docker.ProjectActionResult, override ProtectionOverride) {
+	action := result.Action
+	if action == "down" {
+		action = "remove"
+	}
+	for _, step := range result.Steps {
+		if step.Resource != "container" || step.Code == docker.ResultSkipped {
+			continue
+		}
+		record := h.newHistoryRecord(r, action)
+		record.ContainerID = step.ID
+		record.ContainerNames = []string{step.Name}
+		record.Image = step.Image
+
+		var stepErr error
+		if step.Failed {
+			stepErr = errors.New(step.Message)
+		}
+		message := step.Message + " (project " + result.Project + " " + result.Action + ")"
+		if result.Action == "down" {
+			message = overrideNote(message, override)
+		}
+		h.saveHistory(record, message, stepErr)
+	}
+}
+
+// StartProjectHandler starts the containers of a project, dependencies first
+func (h *Handlers) StartProjectHandler(w http.ResponseWriter, r *http.Request) {
+	h.projectAction(w, r, "start", func(ctx context.Context, dockerService docker.Service, req ProjectActionRequest, _ *docker.ProtectionPolicy) (docker.ProjectActionResult, error) {
+		return dockerService.StartProject(ctx, req.Project)
+	})
+}
+
+// StopProjectHandler stops the containers of a project, dependents first
+func (h *Handlers) StopProjectHandler(w http.ResponseWriter, r *http.Request) {
+	h.projectAction(w, r, "stop", func(ctx context.Context, dockerService docker.Service, req ProjectActionRequest, protection *docker.ProtectionPolicy) (docker.ProjectActionResult, error) {
+		return dockerService.StopProject(ctx, req.Project, req.options(protection))
+	})
+}
+
+// RestartProjectHandler restarts the containers of a project, dependencies first
+func (h *Handlers) RestartProjectHandler(w http.ResponseWriter, r *http.Request) {
+	h.projectAction(w, r, "restart", func(ctx context.Context, dockerService docker.Service, req ProjectActionRequest, protection *docker.ProtectionPolicy) (docker.ProjectActionResult, error) {
+		return dockerService.RestartProject(ctx, req.Project, req.options(protection))
+	})
+}
+
+// DownProjectHandler stops and removes the containers and networks of a
+// project, and its volumes when volumes is true. Protected resources are
+// kept unless the request overrides protection.
+func (h *Handlers) DownProjectHandler(w http.ResponseWriter, r *http.Request) {
+	h.projectAction(w, r, "down", func(ctx context.Context, dockerService docker.Service, req ProjectActionRequest, protection *docker.ProtectionPolicy) (docker.ProjectActionResult, error) {
+		return dockerService.DownProject(ctx, req.Project, req.options(protection))
+	})
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+
+	"Docker_Management/pkg/docker"
+)
+
+// ProtectionOverride lets a single removal request delete a protected resource.
+// It is embedded in the removal request bodies.
+type ProtectionOverride struct {
+	Override bool   `json:"override"`
+	Reason   string `json:"reason"` // Required with override, and kept in the history log
+}
+
+// protectionFor returns the policy a removal must respect: none when the
+// request overrides it, which must come with a reason.
+func (h *Handlers) protectionFor(override ProtectionOverride) (*docker.ProtectionPolicy, error) {
+	if !override.Override {
+		return h.protection, nil
+	}
+	if override.Reason == "" {
+		return nil, errors.New("A reason is required to override deletion protection")
+	}
+	return nil, nil
+}
+
+// overrideNote annotates a history message with the override reason, if any.
+func overrideNote(message string, override ProtectionOverride) string {
+	if !override.Override {
+		return message
+	}
+	return message + " (protection overridden: " + override.Reason + ")"
+}
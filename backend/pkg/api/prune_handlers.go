@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Docker_Management/pkg/docker"
+)
+
+// PruneHandler removes unused containers, images, volumes, networks and build
+// cache. The JSON body is a docker.PruneOptions; with dry_run set nothing is
+// removed and the response lists exactly what would be.
+func (h *Handlers) PruneHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Decode the request body; an empty body prunes everything with the defaults
+	var options docker.PruneOptions
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+	if err := docker.ValidatePruneOptions(options); err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var report docker.PruneReport
+	if options.DryRun {
+		report, err = dockerService.Prune(r.Context(), options, h.protection)
+	} else {
+		record := h.newHistoryRecord(r, "prune")
+		report, err = dockerService.Prune(r.Context(), options, h.protection)
+		h.saveHistory(record, pruneSummary(report), err)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to prune", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// pruneSummary describes a prune report for the history log.
+func pruneSummary(report docker.PruneReport) string {
+	parts := []string{}
+	for _, result := range report.Results {
+		part := fmt.Sprintf("%s: %d removed", result.Type, len(result.Items))
+		if len(result.Skipped) > 0 {
+			part += fmt.Sprintf(", %d protected", len(result.Skipped))
+		}
+		if len(result.Failed) > 0 {
+			part += fmt.Sprintf(", %d failed", len(result.Failed))
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("%s; %d bytes reclaimed", strings.Join(parts, "; "), report.SpaceReclaimed)
+}
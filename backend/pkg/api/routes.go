@@ -1,40 +1,204 @@
 package api
 
 import (
+	"net/http"
+
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/config"
+	"Docker_Management/pkg/db"
+	"Docker_Management/pkg/docker"
+
 	"github.com/gorilla/mux"
 )
 
-func SetupRouter() *mux.Router {
+// Handlers serves the HTTP API on top of the registered Docker hosts.
+// Every route accepts a "host" query parameter selecting the target host.
+type Handlers struct {
+	auth        *auth.Authenticator
+	hosts       *docker.HostRegistry
+	events      *docker.EventHub
+	jobs        *docker.JobManager
+	credentials *docker.CredentialStore
+	protection  *docker.ProtectionPolicy
+	history     db.HistoryStore
+	audit       db.AuditStore
+	openAPI     []byte // OpenAPI document of the routes, built once they are registered
+	cors        *corsPolicy
+	buildRoot   string // Directory that build context paths are confined to
+}
+
+// SetupRouter registers every route with the least role allowed to call it:
+// viewers read, operators run and change containers and images, and admins
+// remove resources. Every POST and DELETE is written to the audit log, and
+// every request passes through the middleware configured by httpConfig.
+//
+// The routes are served under APIPrefix, and the original unversioned routes
+// remain as deprecated aliases. All of them are described by the OpenAPI
+// document at APIPrefix/openapi.json, browsable at /docs.
+func SetupRouter(httpConfig config.HTTPConfig, authenticator *auth.Authenticator, hosts *docker.HostRegistry, events *docker.EventHub, jobs *docker.JobManager, credentials *docker.CredentialStore, protection *docker.ProtectionPolicy, history db.HistoryStore, audit db.AuditStore) http.Handler {
+	h := &Handlers{auth: authenticator, hosts: hosts, events: events, jobs: jobs, credentials: credentials, protection: protection, history: history, audit: audit, cors: newCORSPolicy(httpConfig), buildRoot: httpConfig.BuildRoot}
+
 	router := mux.NewRouter()
-	router.HandleFunc("/containers", ListContainersHandler).Methods("GET")
-	router.HandleFunc("/containers/all", ListAllContainersHandler).Methods("GET")
-	router.HandleFunc("/containers/start", StartContainerHandler).Methods("POST")
-	router.HandleFunc("/containers/stop", StopContainerHandler).Methods("POST")
-	router.HandleFunc("/containers/remove", RemoveContainerHandler).Methods("DELETE")
-	router.HandleFunc("/containers/logs", GetContainerLogsHandler).Methods("POST")
-	router.HandleFunc("/containers/stats", GetContainerStatsHandler).Methods("POST")
-	router.HandleFunc("/containers/inspect", InspectContainerHandler).Methods("POST")
-	router.HandleFunc("/containers/remove/all", RemoveAllContainersHandler).Methods("DELETE")
-
-	router.HandleFunc("/images", ListImagesHandler).Methods("GET")
-	router.HandleFunc("/images/dangling", ListDanglingImagesHandler).Methods("GET")
-	router.HandleFunc("/images/remove", RemoveImageHandler).Methods("DELETE")							
-	router.HandleFunc("/images/remove/all", RemoveAllImagesHandler).Methods("DELETE")
-	router.HandleFunc("/images/dangling/remove/all", RemoveAllDanglingImagesHandler).Methods("DELETE")
-	router.HandleFunc("/images/inspect", InspectImageHandler).Methods("POST")
-	router.HandleFunc("/images/pull", PullImageHandler).Methods("POST")
-
-
-	router.HandleFunc("/volumes", ListVolumesHandler).Methods("GET")
-	router.HandleFunc("/volumes/inspect", InspectVolumeHandler).Methods("POST")
-	router.HandleFunc("/volumes/containers", ListContainersAttachedToVolumeHandler).Methods("POST")
-	router.HandleFunc("/volumes/remove", RemoveVolumeHandler).Methods("DELETE")
-
-
-	router.HandleFunc("/networks", ListNetworksHandler).Methods("GET")
-	router.HandleFunc("/networks/inspect", InspectNetworkHandler).Methods("POST")
-	router.HandleFunc("/networks/containers", ListContainersInNetworkHandler).Methods("POST")
-	router.HandleFunc("/networks/remove", RemoveNetworkHandler).Methods("DELETE")
-	
-	return router
+	router.Use(h.auditMiddleware)
+
+	router.HandleFunc("/docs", h.SwaggerUIHandler).Methods("GET").Name("swaggerUI")
+
+	// Versioned routes take the resource from the path
+	v1 := router.PathPrefix(APIPrefix).Subrouter()
+	v1.HandleFunc("/openapi.json", h.OpenAPIHandler).Methods("GET").Name("getOpenAPI")
+	v1.HandleFunc("/auth/login", h.LoginHandler).Methods("POST").Name("login")
+	v1.HandleFunc("/auth/me", h.require(auth.RoleViewer, h.WhoAmIHandler)).Methods("GET").Name("whoAmI")
+
+	v1.HandleFunc("/hosts", h.require(auth.RoleViewer, h.ListHostsHandler)).Methods("GET").Name("listHosts")
+	v1.HandleFunc("/history", h.require(auth.RoleViewer, h.ListHistoryHandler)).Methods("GET").Name("listHistory")
+	v1.HandleFunc("/audit", h.require(auth.RoleAdmin, h.ListAuditHandler)).Methods("GET").Name("listAudit")
+	v1.HandleFunc("/events", h.require(auth.RoleViewer, h.StreamEventsHandler)).Methods("GET").Name("streamEvents")
+	v1.HandleFunc("/prune", h.require(auth.RoleAdmin, h.PruneHandler)).Methods("POST").Name("prune")
+
+	v1.HandleFunc("/jobs", h.require(auth.RoleViewer, h.ListJobsHandler)).Methods("GET").Name("listJobs")
+	v1.HandleFunc("/jobs/{id}", h.require(auth.RoleViewer, h.InspectJobHandler)).Methods("GET").Name("getJob")
+	v1.HandleFunc("/jobs/{id}/stream", h.require(auth.RoleViewer, h.StreamJobHandler)).Methods("GET").Name("streamJob")
+	v1.HandleFunc("/jobs/{id}/cancel", h.require(auth.RoleOperator, h.CancelJobHandler)).Methods("POST").Name("cancelJob")
+
+	v1.HandleFunc("/containers", h.require(auth.RoleViewer, h.ListContainersHandler)).Methods("GET").Name("listContainers")
+	v1.HandleFunc("/containers", h.require(auth.RoleOperator, h.CreateContainerHandler)).Methods("POST").Name("createContainer")
+	v1.HandleFunc("/containers", h.require(auth.RoleAdmin, h.RemoveAllContainersHandler)).Methods("DELETE").Name("removeAllContainers")
+	v1.HandleFunc("/containers/run", h.require(auth.RoleOperator, h.RunContainerHandler)).Methods("POST").Name("runContainer")
+	v1.HandleFunc("/containers/stats/stream", h.require(auth.RoleViewer, h.StreamContainerStatsHandler)).Methods("GET").Name("streamContainerStats")
+	v1.HandleFunc("/containers/{id}", h.require(auth.RoleViewer, h.InspectContainerHandler)).Methods("GET").Name("inspectContainer")
+	v1.HandleFunc("/containers/{id}", h.require(auth.RoleAdmin, h.RemoveContainerHandler)).Methods("DELETE").Name("removeContainer")
+	v1.HandleFunc("/containers/{id}/start", h.require(auth.RoleOperator, h.StartContainerHandler)).Methods("POST").Name("startContainer")
+	v1.HandleFunc("/containers/{id}/stop", h.require(auth.RoleOperator, h.StopContainerHandler)).Methods("POST").Name("stopContainer")
+	v1.HandleFunc("/containers/{id}/restart", h.require(auth.RoleOperator, h.RestartContainerHandler)).Methods("POST").Name("restartContainer")
+	v1.HandleFunc("/containers/{id}/pause", h.require(auth.RoleOperator, h.PauseContainerHandler)).Methods("POST").Name("pauseContainer")
+	v1.HandleFunc("/containers/{id}/unpause", h.require(auth.RoleOperator, h.UnpauseContainerHandler)).Methods("POST").Name("unpauseContainer")
+	v1.HandleFunc("/containers/{id}/kill", h.require(auth.RoleOperator, h.KillContainerHandler)).Methods("POST").Name("killContainer")
+	v1.HandleFunc("/containers/{id}/rename", h.require(auth.RoleOperator, h.RenameContainerHandler)).Methods("POST").Name("renameContainer")
+	v1.HandleFunc("/containers/{id}/wait", h.require(auth.RoleOperator, h.WaitContainerHandler)).Methods("POST").Name("waitContainer")
+	v1.HandleFunc("/containers/{id}/exec", h.require(auth.RoleOperator, h.CreateExecHandler)).Methods("POST").Name("createExec")
+	v1.HandleFunc("/containers/{id}/logs", h.require(auth.RoleViewer, h.GetContainerLogsHandler)).Methods("GET").Name("getContainerLogs")
+	v1.HandleFunc("/containers/{id}/logs/stream", h.require(auth.RoleViewer, h.StreamContainerLogsHandler)).Methods("GET").Name("streamContainerLogs")
+	v1.HandleFunc("/containers/{id}/stats", h.require(auth.RoleViewer, h.GetContainerStatsHandler)).Methods("GET").Name("getContainerStats")
+	v1.HandleFunc("/containers/{id}/export", h.require(auth.RoleOperator, h.ExportContainerHandler)).Methods("GET").Name("exportContainer")
+	v1.HandleFunc("/exec/{exec}/attach", h.require(auth.RoleOperator, h.AttachExecHandler)).Methods("GET").Name("attachExec")
+
+	// Image references may contain slashes, so {ref} matches them and the
+	// literal routes come first
+	v1.HandleFunc("/images", h.require(auth.RoleViewer, h.ListImagesHandler)).Methods("GET").Name("listImages")
+	v1.HandleFunc("/images", h.require(auth.RoleAdmin, h.RemoveAllImagesHandler)).Methods("DELETE").Name("removeAllImages")
+	v1.HandleFunc("/images/dangling", h.require(auth.RoleViewer, h.ListDanglingImagesHandler)).Methods("GET").Name("listDanglingImages")
+	v1.HandleFunc("/images/dangling", h.require(auth.RoleAdmin, h.RemoveAllDanglingImagesHandler)).Methods("DELETE").Name("removeDanglingImages")
+	v1.HandleFunc("/images/usage", h.require(auth.RoleViewer, h.ImageUsageHandler)).Methods("GET").Name("imageUsage")
+	v1.HandleFunc("/images/pull", h.require(auth.RoleOperator, h.PullImageHandler)).Methods("POST").Name("pullImage")
+	v1.HandleFunc("/images/pull/jobs", h.require(auth.RoleOperator, h.PullImageJobHandler)).Methods("POST").Name("pullImageJob")
+	v1.HandleFunc("/images/build", h.require(auth.RoleOperator, h.BuildImageHandler)).Methods("POST").Name("buildImage")
+	v1.HandleFunc("/images/mirror/jobs", h.require(auth.RoleOperator, h.MirrorImageJobHandler)).Methods("POST").Name("mirrorImageJob")
+	v1.HandleFunc("/images/save", h.require(auth.RoleOperator, h.SaveImagesHandler)).Methods("GET").Name("saveImages")
+	v1.HandleFunc("/images/load", h.require(auth.RoleOperator, h.LoadImagesHandler)).Methods("POST").Name("loadImages")
+	v1.HandleFunc("/images/import", h.require(auth.RoleOperator, h.ImportImageHandler)).Methods("POST").Name("importImage")
+	v1.HandleFunc("/images/{ref:.+}/json", h.require(auth.RoleViewer, h.InspectImageHandler)).Methods("GET").Name("inspectImage")
+	v1.HandleFunc("/images/{ref:.+}/history", h.require(auth.RoleViewer, h.ImageHistoryHandler)).Methods("GET").Name("imageHistory")
+	v1.HandleFunc("/images/{ref:.+}/tag", h.require(auth.RoleOperator, h.TagImageHandler)).Methods("POST").Name("tagImage")
+	v1.HandleFunc("/images/{ref:.+}/tag", h.require(auth.RoleOperator, h.UntagImageHandler)).Methods("DELETE").Name("untagImage")
+	v1.HandleFunc("/images/{ref:.+}/push/jobs", h.require(auth.RoleOperator, h.PushImageJobHandler)).Methods("POST").Name("pushImageJob")
+	v1.HandleFunc("/images/{ref:.+}", h.require(auth.RoleAdmin, h.RemoveImageHandler)).Methods("DELETE").Name("removeImage")
+
+	v1.HandleFunc("/volumes", h.require(auth.RoleViewer, h.ListVolumesHandler)).Methods("GET").Name("listVolumes")
+	v1.HandleFunc("/volumes/{name}", h.require(auth.RoleViewer, h.InspectVolumeHandler)).Methods("GET").Name("inspectVolume")
+	v1.HandleFunc("/volumes/{name}", h.require(auth.RoleAdmin, h.RemoveVolumeHandler)).Methods("DELETE").Name("removeVolume")
+	v1.HandleFunc("/volumes/{name}/containers", h.require(auth.RoleViewer, h.ListContainersAttachedToVolumeHandler)).Methods("GET").Name("listVolumeContainers")
+
+	v1.HandleFunc("/networks", h.require(auth.RoleViewer, h.ListNetworksHandler)).Methods("GET").Name("listNetworks")
+	v1.HandleFunc("/networks/{id}", h.require(auth.RoleViewer, h.InspectNetworkHandler)).Methods("GET").Name("inspectNetwork")
+	v1.HandleFunc("/networks/{id}", h.require(auth.RoleAdmin, h.RemoveNetworkHandler)).Methods("DELETE").Name("removeNetwork")
+	v1.HandleFunc("/networks/{id}/containers", h.require(auth.RoleViewer, h.ListContainersInNetworkHandler)).Methods("GET").Name("listNetworkContainers")
+
+	// Compose projects group resources by their compose labels
+	v1.HandleFunc("/projects", h.require(auth.RoleViewer, h.ListProjectsHandler)).Methods("GET").Name("listProjects")
+	v1.HandleFunc("/projects/{project}", h.require(auth.RoleViewer, h.InspectProjectHandler)).Methods("GET").Name("inspectProject")
+	v1.HandleFunc("/projects/{project}/logs/stream", h.require(auth.RoleViewer, h.StreamProjectLogsHandler)).Methods("GET").Name("streamProjectLogs")
+	v1.HandleFunc("/projects/{project}/start", h.require(auth.RoleOperator, h.StartProjectHandler)).Methods("POST").Name("startProject")
+	v1.HandleFunc("/projects/{project}/stop", h.require(auth.RoleOperator, h.StopProjectHandler)).Methods("POST").Name("stopProject")
+	v1.HandleFunc("/projects/{project}/restart", h.require(auth.RoleOperator, h.RestartProjectHandler)).Methods("POST").Name("restartProject")
+	v1.HandleFunc("/projects/{project}/down", h.require(auth.RoleAdmin, h.DownProjectHandler)).Methods("POST").Name("downProject")
+
+	// Legacy routes take the resource in the body or query, and are kept as
+	// deprecated aliases of the versioned ones
+	legacy := router.NewRoute().Subrouter()
+	legacy.Use(deprecated)
+	legacy.HandleFunc("/openapi.json", h.OpenAPIHandler).Methods("GET").Name("legacy.getOpenAPI")
+	legacy.HandleFunc("/auth/login", h.LoginHandler).Methods("POST").Name("legacy.login")
+	legacy.HandleFunc("/auth/me", h.require(auth.RoleViewer, h.WhoAmIHandler)).Methods("GET").Name("legacy.whoAmI")
+
+	legacy.HandleFunc("/hosts", h.require(auth.RoleViewer, h.ListHostsHandler)).Methods("GET").Name("legacy.listHosts")
+	legacy.HandleFunc("/history", h.require(auth.RoleViewer, h.ListHistoryHandler)).Methods("GET").Name("legacy.listHistory")
+	legacy.HandleFunc("/audit", h.require(auth.RoleAdmin, h.ListAuditHandler)).Methods("GET").Name("legacy.listAudit")
+	legacy.HandleFunc("/events", h.require(auth.RoleViewer, h.StreamEventsHandler)).Methods("GET").Name("legacy.streamEvents")
+	legacy.HandleFunc("/prune", h.require(auth.RoleAdmin, h.PruneHandler)).Methods("POST").Name("legacy.prune")
+
+	legacy.HandleFunc("/jobs", h.require(auth.RoleViewer, h.ListJobsHandler)).Methods("GET").Name("legacy.listJobs")
+	legacy.HandleFunc("/jobs/inspect", h.require(auth.RoleViewer, h.InspectJobHandler)).Methods("GET").Name("legacy.getJob")
+	legacy.HandleFunc("/jobs/stream", h.require(auth.RoleViewer, h.StreamJobHandler)).Methods("GET").Name("legacy.streamJob")
+	legacy.HandleFunc("/jobs/cancel", h.require(auth.RoleOperator, h.CancelJobHandler)).Methods("POST").Name("legacy.cancelJob")
+
+	legacy.HandleFunc("/containers", h.require(auth.RoleViewer, h.ListContainersHandler)).Methods("GET").Name("legacy.listContainers")
+	legacy.HandleFunc("/containers/all", h.require(auth.RoleViewer, h.ListAllContainersHandler)).Methods("GET").Name("legacy.listAllContainers")
+	legacy.HandleFunc("/containers/create", h.require(auth.RoleOperator, h.CreateContainerHandler)).Methods("POST").Name("legacy.createContainer")
+	legacy.HandleFunc("/containers/run", h.require(auth.RoleOperator, h.RunContainerHandler)).Methods("POST").Name("legacy.runContainer")
+	legacy.HandleFunc("/containers/start", h.require(auth.RoleOperator, h.StartContainerHandler)).Methods("POST").Name("legacy.startContainer")
+	legacy.HandleFunc("/containers/stop", h.require(auth.RoleOperator, h.StopContainerHandler)).Methods("POST").Name("legacy.stopContainer")
+	legacy.HandleFunc("/containers/restart", h.require(auth.RoleOperator, h.RestartContainerHandler)).Methods("POST").Name("legacy.restartContainer")
+	legacy.HandleFunc("/containers/pause", h.require(auth.RoleOperator, h.PauseContainerHandler)).Methods("POST").Name("legacy.pauseContainer")
+	legacy.HandleFunc("/containers/unpause", h.require(auth.RoleOperator, h.UnpauseContainerHandler)).Methods("POST").Name("legacy.unpauseContainer")
+	legacy.HandleFunc("/containers/kill", h.require(auth.RoleOperator, h.KillContainerHandler)).Methods("POST").Name("legacy.killContainer")
+	legacy.HandleFunc("/containers/rename", h.require(auth.RoleOperator, h.RenameContainerHandler)).Methods("POST").Name("legacy.renameContainer")
+	legacy.HandleFunc("/containers/wait", h.require(auth.RoleOperator, h.WaitContainerHandler)).Methods("POST").Name("legacy.waitContainer")
+	legacy.HandleFunc("/containers/exec", h.require(auth.RoleOperator, h.CreateExecHandler)).Methods("POST").Name("legacy.createExec")
+	legacy.HandleFunc("/containers/exec/attach", h.require(auth.RoleOperator, h.AttachExecHandler)).Methods("GET").Name("legacy.attachExec")
+	legacy.HandleFunc("/containers/remove", h.require(auth.RoleAdmin, h.RemoveContainerHandler)).Methods("DELETE").Name("legacy.removeContainer")
+	legacy.HandleFunc("/containers/logs", h.require(auth.RoleViewer, h.GetContainerLogsHandler)).Methods("POST").Name("legacy.getContainerLogs")
+	legacy.HandleFunc("/containers/logs/stream", h.require(auth.RoleViewer, h.StreamContainerLogsHandler)).Methods("GET").Name("legacy.streamContainerLogs")
+	legacy.HandleFunc("/containers/stats", h.require(auth.RoleViewer, h.GetContainerStatsHandler)).Methods("POST").Name("legacy.getContainerStats")
+	legacy.HandleFunc("/containers/stats/stream", h.require(auth.RoleViewer, h.StreamContainerStatsHandler)).Methods("GET").Name("legacy.streamContainerStats")
+	legacy.HandleFunc("/containers/inspect", h.require(auth.RoleViewer, h.InspectContainerHandler)).Methods("POST").Name("legacy.inspectContainer")
+	legacy.HandleFunc("/containers/export", h.require(auth.RoleOperator, h.ExportContainerHandler)).Methods("GET").Name("legacy.exportContainer")
+	legacy.HandleFunc("/containers/remove/all", h.require(auth.RoleAdmin, h.RemoveAllContainersHandler)).Methods("DELETE").Name("legacy.removeAllContainers")
+
+	legacy.HandleFunc("/images", h.require(auth.RoleViewer, h.ListImagesHandler)).Methods("GET").Name("legacy.listImages")
+	legacy.HandleFunc("/images/dangling", h.require(auth.RoleViewer, h.ListDanglingImagesHandler)).Methods("GET").Name("legacy.listDanglingImages")
+	legacy.HandleFunc("/images/remove", h.require(auth.RoleAdmin, h.RemoveImageHandler)).Methods("DELETE").Name("legacy.removeImage")
+	legacy.HandleFunc("/images/remove/all", h.require(auth.RoleAdmin, h.RemoveAllImagesHandler)).Methods("DELETE").Name("legacy.removeAllImages")
+	legacy.HandleFunc("/images/dangling/remove/all", h.require(auth.RoleAdmin, h.RemoveAllDanglingImagesHandler)).Methods("DELETE").Name("legacy.removeDanglingImages")
+	legacy.HandleFunc("/images/inspect", h.require(auth.RoleViewer, h.InspectImageHandler)).Methods("POST").Name("legacy.inspectImage")
+	legacy.HandleFunc("/images/history", h.require(auth.RoleViewer, h.ImageHistoryHandler)).Methods("GET").Name("legacy.imageHistory")
+	legacy.HandleFunc("/images/usage", h.require(auth.RoleViewer, h.ImageUsageHandler)).Methods("GET").Name("legacy.imageUsage")
+	legacy.HandleFunc("/images/pull", h.require(auth.RoleOperator, h.PullImageHandler)).Methods("POST").Name("legacy.pullImage")
+	legacy.HandleFunc("/images/pull/jobs", h.require(auth.RoleOperator, h.PullImageJobHandler)).Methods("POST").Name("legacy.pullImageJob")
+	legacy.HandleFunc("/images/build", h.require(auth.RoleOperator, h.BuildImageHandler)).Methods("POST").Name("legacy.buildImage")
+	legacy.HandleFunc("/images/tag", h.require(auth.RoleOperator, h.TagImageHandler)).Methods("POST").Name("legacy.tagImage")
+	legacy.HandleFunc("/images/untag", h.require(auth.RoleOperator, h.UntagImageHandler)).Methods("DELETE").Name("legacy.untagImage")
+	legacy.HandleFunc("/images/push/jobs", h.require(auth.RoleOperator, h.PushImageJobHandler)).Methods("POST").Name("legacy.pushImageJob")
+	legacy.HandleFunc("/images/mirror/jobs", h.require(auth.RoleOperator, h.MirrorImageJobHandler)).Methods("POST").Name("legacy.mirrorImageJob")
+	legacy.HandleFunc("/images/save", h.require(auth.RoleOperator, h.SaveImagesHandler)).Methods("GET").Name("legacy.saveImages")
+	legacy.HandleFunc("/images/load", h.require(auth.RoleOperator, h.LoadImagesHandler)).Methods("POST").Name("legacy.loadImages")
+	legacy.HandleFunc("/images/import", h.require(auth.RoleOperator, h.ImportImageHandler)).Methods("POST").Name("legacy.importImage")
+
+	legacy.HandleFunc("/volumes", h.require(auth.RoleViewer, h.ListVolumesHandler)).Methods("GET").Name("legacy.listVolumes")
+	legacy.HandleFunc("/volumes/inspect", h.require(auth.RoleViewer, h.InspectVolumeHandler)).Methods("POST").Name("legacy.inspectVolume")
+	legacy.HandleFunc("/volumes/containers", h.require(auth.RoleViewer, h.ListContainersAttachedToVolumeHandler)).Methods("POST").Name("legacy.listVolumeContainers")
+	legacy.HandleFunc("/volumes/remove", h.require(auth.RoleAdmin, h.RemoveVolumeHandler)).Methods("DELETE").Name("legacy.removeVolume")
+
+	legacy.HandleFunc("/networks", h.require(auth.RoleViewer, h.ListNetworksHandler)).Methods("GET").Name("legacy.listNetworks")
+	legacy.HandleFunc("/networks/inspect", h.require(auth.RoleViewer, h.InspectNetworkHandler)).Methods("POST").Name("legacy.inspectNetwork")
+	legacy.HandleFunc("/networks/containers", h.require(auth.RoleViewer, h.ListContainersInNetworkHandler)).Methods("POST").Name("legacy.listNetworkContainers")
+	legacy.HandleFunc("/networks/remove", h.require(auth.RoleAdmin, h.RemoveNetworkHandler)).Methods("DELETE").Name("legacy.removeNetwork")
+
+	// Every route must be documented, so an undocumented one is a programming error
+	openAPI, err := buildOpenAPI(router)
+	if err != nil {
+		panic("api: " + err.Error())
+	}
+	h.openAPI = openAPI
+
+	return newMiddlewareChain(router, httpConfig, h.cors)
 }
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sseStream writes Server-Sent Events to a client, flushing after every event.
+type sseStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEStream prepares w for an event stream and sends the response headers.
+func newSSEStream(w http.ResponseWriter) (*sseStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported by this connection")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseStream{w: w, flusher: flusher}, nil
+}
+
+// Send writes one event. Multi-line data is split across several data fields.
+func (s *sseStream) Send(event string, data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// SendJSON writes one event whose data is v encoded as JSON.
+func (s *sseStream) SendJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(event, string(data))
+}
+
+// Ping writes a comment line that keeps idle connections from timing out.
+func (s *sseStream) Ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// sseLineWriter is an io.Writer that emits one event per complete line.
+// When format is set, each line is sent as the JSON encoding of format(line).
+type sseLineWriter struct {
+	stream  *sseStream
+	event   string
+	format  func(line string) interface{}
+	pending []byte
+}
+
+func (lw *sseLineWriter) Write(p []byte) (int, error) {
+	lw.pending = append(lw.pending, p...)
+	for {
+		i := bytes.IndexByte(lw.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSuffix(string(lw.pending[:i]), "\r")
+		lw.pending = lw.pending[i+1:]
+		if err := lw.send(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush emits any trailing partial line.
+func (lw *sseLineWriter) Flush() error {
+	if len(lw.pending) == 0 {
+		return nil
+	}
+	line := string(lw.pending)
+	lw.pending = nil
+	return lw.send(line)
+}
+
+func (lw *sseLineWriter) send(line string) error {
+	if lw.format != nil {
+		return lw.stream.SendJSON(lw.event, lw.format(line))
+	}
+	return lw.stream.Send(lw.event, line)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"Docker_Management/pkg/docker"
+
+	"github.com/gorilla/mux"
+)
+
+// maxTargetBody is how much of a request body is read to find its target.
+// Larger bodies, such as tarball uploads, are not inspected.
+const maxTargetBody = 64 << 10
+
+// targetFields are the path variables, query parameters and JSON body fields
+// naming the resource a route acts on, by the first path segment of the route.
+var targetFields = map[string][]string{
+	"containers": {"id"},
+	"images":     {"ref", "id", "image", "source"},
+	"volumes":    {"name"},
+	"networks":   {"id"},
+}
+
+// requestTarget returns the type of resource a request acts on and every ID
+// or name it gives for it, in the path, the query or the body, so that none
+// escapes the scope check. Exec sessions are returned by exec ID with an
+// empty type. The request body is left intact for the handler.
+func requestTarget(r *http.Request) (docker.PruneType, []string, bool) {
+	if execID := pathParam(r, "exec"); execID != "" {
+		return "", []string{execID}, true
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, APIPrefix)
+	segment := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+	fields, ok := targetFields[segment]
+	if !ok {
+		return "", nil, false
+	}
+
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	body := bodyTargets(peekBody(r), fields)
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, field := range fields {
+		add(vars[field])
+		for _, id := range query[field] {
+			add(id)
+		}
+	}
+	for _, id := range body {
+		add(id)
+	}
+
+	return docker.PruneType(segment), ids, true
+}
+
+// peekBody reads a small request body without consuming it. It returns nil
+// for empty bodies and for those too large to inspect.
+func peekBody(r *http.Request) []byte {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	head, _ := io.ReadAll(io.LimitReader(r.Body, maxTargetBody+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if len(head) > maxTargetBody {
+		return nil
+	}
+	return head
+}
+
+// peekJSONBody decodes a small JSON object body without consuming it. It
+// returns nil for other bodies.
+func peekJSONBody(r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	if json.Unmarshal(peekBody(r), &body) != nil {
+		return nil
+	}
+	return body
+}
+
+// bodyTargets returns the string values of every top-level key of a JSON
+// object that names one of fields. Keys are matched without regard to case
+// and duplicates are all kept, since the handlers decode into structs, which
+// accept any case and keep the last duplicate.
+func bodyTargets(body []byte, fields []string) []string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+
+	var ids []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return ids
+		}
+		key, _ := token.(string)
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return ids
+		}
+		id, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			if strings.EqualFold(key, field) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// readCloser reads from a replacement reader but closes the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
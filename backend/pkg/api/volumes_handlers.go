@@ -1,66 +1,121 @@
 package api
 
 import (
-	"Docker_Management/pkg/docker" // replace with actual path to docker package
 	"encoding/json"
 	"net/http"
+	"time"
+
+	"github.com/docker/docker/api/types"
 )
 
-// ListVolumesHandler is an HTTP handler to list all Docker volumes
-func ListVolumesHandler(w http.ResponseWriter, r *http.Request) {
-	volumes, err := docker.ListVolumes()
+// VolumeResponse is a volume in the volume list
+type VolumeResponse struct {
+	Host       string `json:"Host"` // Docker host the volume is stored on
+	Name       string `json:"Name"`
+	Driver     string `json:"Driver"`
+	Mountpoint string `json:"Mountpoint"`
+	CreatedAt  string `json:"CreatedAt,omitempty"`
+}
+
+// VolumePage is a page of the volume list
+type VolumePage struct {
+	Total      int              `json:"total"` // Matching volumes in all pages
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Items      []VolumeResponse `json:"items"`
+}
+
+// hostVolume is a volume as listed by the daemon of host
+type hostVolume struct {
+	host string
+	*types.Volume
+}
+
+func volumeSortKey(volume hostVolume, field string) sortKey {
+	key := sortKey{ID: volume.host + "/" + volume.Name}
+	switch field {
+	case "created":
+		if created, err := time.Parse(time.RFC3339, volume.CreatedAt); err == nil {
+			key.Number = created.UnixNano()
+		}
+	case "name":
+		key.Text = volume.Name
+	}
+	return key
+}
+
+// ListVolumesHandler is an HTTP handler to list all Docker volumes. The
+// label, name, dangling and driver query parameters filter the list on the
+// daemon.
+func (h *Handlers) ListVolumesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := parseListQuery(r, "created", "name")
 	if err != nil {
-		http.Error(w, "Failed to list volumes", http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	if len(volumes) == 0 {
-		// If no volumes are present, return an appropriate message
-		response := map[string]string{"message": "No Volumes are present"}
-		json.NewEncoder(w).Encode(response)
+	targets, err := h.targetHosts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	// Format volumes as a JSON response
-	response := []map[string]string{}
-	for _, volume := range volumes {
-		response = append(response, map[string]string{
-			"Name":       volume.Name,
-			"Driver":     volume.Driver,
-			"Mountpoint": volume.Mountpoint,
-		})
+	filter := listFilters(r.URL.Query(), volumeFilters)
+	principal := principalFor(r)
+	var rows []hostVolume
+	for _, target := range targets {
+		volumes, err := target.docker.ListVolumes(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to list volumes on host "+target.name, err)
+			return
+		}
+		for _, volume := range volumes {
+			// Callers scoped by label only see their own volumes
+			if !principal.InScope(volume.Labels) {
+				continue
+			}
+			rows = append(rows, hostVolume{host: target.name, Volume: volume})
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
-	json.NewEncoder(w).Encode(response)
+	// Format volumes as a JSON response, labelling each row with its host
+	page, total, next := paginate(rows, list, volumeSortKey)
+	response := []VolumeResponse{}
+	for _, volume := range page {
+		response = append(response, VolumeResponse{
+			Host:       volume.host,
+			Name:       volume.Name,
+			Driver:     volume.Driver,
+			Mountpoint: volume.Mountpoint,
+			CreatedAt:  volume.CreatedAt,
+		})
+	}
+	writeList(w, r, response, VolumePage{Total: total, NextCursor: next, Items: response})
 }
 
-func InspectVolumeHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) InspectVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	// Decode the request body
 	var reqBody RequestBodyVolume
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the function to inspect the volume
-	volume, err := docker.InspectVolume(reqBody.Name)
+	volume, err := dockerService.InspectVolume(r.Context(), reqBody.Name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	if err := json.NewEncoder(w).Encode(volume); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeErrorMessage(w, http.StatusInternalServerError, "Failed to encode response")
 		return
 	}
 }
@@ -69,24 +124,36 @@ type RequestBodyVolume struct {
 	Name string `json:"name"` // Ensure the field is defined as "Name" (capitalized to export it)
 }
 
+// RemoveVolumeRequest names the volume to remove
+type RemoveVolumeRequest struct {
+	Name string `json:"name"`
+	ProtectionOverride
+}
+
 type ResponseVolumeContainers struct {
 	Message      string   `json:"message"`
 	ContainerIDs []string `json:"container_ids,omitempty"`
 }
 
 // ListContainersAttachedToVolumeHandler handles requests to list containers attached to a volume
-func ListContainersAttachedToVolumeHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ListContainersAttachedToVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
 	// Parse the request body to get the volume name
 	var reqBody RequestBodyVolume
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
 		return
 	}
 
 	// Call the function to list containers attached to the specified volume
-	containerIDs, err := docker.ListContainersAttachedToVolume(reqBody.Name)
+	containerIDs, err := dockerService.ListContainersAttachedToVolume(r.Context(), reqBody.Name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "", err)
 		return
 	}
 
@@ -98,34 +165,37 @@ func ListContainersAttachedToVolumeHandler(w http.ResponseWriter, r *http.Reques
 
 	// Return the response in JSON format
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeErrorMessage(w, http.StatusInternalServerError, "Failed to encode response")
 		return
 	}
 }
 
-func RemoveVolumeHandler(w http.ResponseWriter, r *http.Request) {
-	var reqBody RequestBodyVolume
-	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+func (h *Handlers) RemoveVolumeHandler(w http.ResponseWriter, r *http.Request) {
+	dockerService, err := h.dockerFor(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var reqBody RemoveVolumeRequest
+	if err := decodeRequest(r, &reqBody); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	protection, err := h.protectionFor(reqBody.ProtectionOverride)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err)
 		return
 	}
 
-	message, err := docker.RemoveVolume(reqBody.Name)
+	message, err := dockerService.RemoveVolume(r.Context(), reqBody.Name, protection)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusInternalServerError, "Failed to remove volume", err)
 		return
 	}
 
-	response := map[string]string{"message": message}
+	response := MessageResponse{Message: message}
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200")
-    w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-    w.Header().Set("Access-Control-Allow-Credentials", "true")
 	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"Docker_Management/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenIssuer identifies the login tokens issued by this backend.
+const tokenIssuer = "docker-management"
+
+// Role grants access to a set of routes. Each role includes the ones below it.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Read-only: lists, inspect, logs, stats, events and history
+	RoleOperator Role = "operator" // Lifecycle, exec, pull, build and push
+	RoleAdmin    Role = "admin"    // Removal, prune and everything else
+)
+
+var roleRanks = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole validates a configured role name.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: expected viewer, operator or admin", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether r includes the required role.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Principal is the caller of an API request.
+type Principal struct {
+	Name   string   `json:"name"`
+	Role   Role     `json:"role"`
+	Method string   `json:"method"`          // password, token or anonymous
+	Scope  []string `json:"scope,omitempty"` // Label selectors every resource must match
+}
+
+// Anonymous is the principal of every request when authentication is disabled.
+var Anonymous = Principal{Name: "anonymous", Role: RoleAdmin, Method: "anonymous"}
+
+// Scoped reports whether the principal is limited to labelled resources.
+func (p Principal) Scoped() bool {
+	return len(p.Scope) > 0
+}
+
+// InScope reports whether a resource with the given labels matches every
+// scope selector, each either key or key=value.
+func (p Principal) InScope(labels map[string]string) bool {
+	for _, selector := range p.Scope {
+		key, value, hasValue := strings.Cut(selector, "=")
+		actual, ok := labels[key]
+		if !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the caller to a request context.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the caller attached by WithPrincipal.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// ErrUnauthenticated is returned for missing, unknown or expired credentials.
+var ErrUnauthenticated = errors.New("invalid or expired credentials")
+
+type user struct {
+	passwordHash []byte
+	role         Role
+	scope        []string
+}
+
+// Authenticator checks passwords and bearer tokens against the configured
+// users and API tokens, and issues login tokens.
+type Authenticator struct {
+	users  map[string]user
+	tokens map[string]Principal // By hex SHA-256 of the token
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewAuthenticator validates the configured users and tokens.
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		users:  map[string]user{},
+		tokens: map[string]Principal{},
+		secret: []byte(cfg.JWTSecret),
+		ttl:    cfg.TokenTTL,
+	}
+
+	for _, configured := range cfg.Users {
+		role, err := ParseRole(configured.Role)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %v", configured.Username, err)
+		}
+		if configured.Username == "" || configured.PasswordHash == "" {
+			return nil, errors.New("every user needs a username and password_hash")
+		}
+		if _, err := bcrypt.Cost([]byte(configured.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("user %s: password_hash is not a bcrypt hash", configured.Username)
+		}
+		a.users[configured.Username] = user{passwordHash: []byte(configured.PasswordHash), role: role, scope: configured.Scope}
+	}
+
+	for _, configured := range cfg.Tokens {
+		role, err := ParseRole(configured.Role)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %v", configured.Name, err)
+		}
+		hash := strings.ToLower(configured.TokenSHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("token %s: token_sha256 is not a hex SHA-256 digest", configured.Name)
+		}
+		a.tokens[hash] = Principal{Name: configured.Name, Role: role, Method: "token", Scope: configured.Scope}
+	}
+
+	if a.ttl <= 0 {
+		a.ttl = 12 * time.Hour
+	}
+	if len(a.secret) == 0 && len(a.users) > 0 {
+		// Login tokens then stop working when the server restarts
+		log.Println("No JWT_SECRET set, signing login tokens with a random secret")
+		a.secret = make([]byte, 32)
+		if _, err := rand.Read(a.secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// Enabled reports whether any users or tokens are configured. Without them
+// every request is served as Anonymous.
+func (a *Authenticator) Enabled() bool {
+	return len(a.users) > 0 || len(a.tokens) > 0
+}
+
+// dummyHash is compared against when the user is unknown, so that a login
+// takes as long whether or not the user exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Login checks a username and password and returns a signed login token with its expiry.
+func (a *Authenticator) Login(username, password string) (string, time.Time, Principal, error) {
+	account, ok := a.users[username]
+	hash := account.passwordHash
+	if !ok {
+		hash = dummyHash
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return "", time.Time{}, Principal{}, ErrUnauthenticated
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   username, // The role and scope are looked up on every request
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	signed, err := token.SignedString(a.secret)
+	if err != nil {
+		return "", time.Time{}, Principal{}, err
+	}
+
+	return signed, expiresAt, a.userPrincipal(username, account), nil
+}
+
+// Authenticate resolves a bearer token, either a login token or an API token.
+func (a *Authenticator) Authenticate(token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	digest := sha256.Sum256([]byte(token))
+	if principal, ok := a.tokens[hex.EncodeToString(digest[:])]; ok {
+		return principal, nil
+	}
+
+	if len(a.secret) == 0 {
+		return Principal{}, ErrUnauthenticated
+	}
+	var parsed jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &parsed, func(*jwt.Token) (interface{}, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	// Role and scope changes apply to tokens already issued
+	account, ok := a.users[parsed.Subject]
+	if !ok {
+		return Principal{}, ErrUnauthenticated
+	}
+	return a.userPrincipal(parsed.Subject, account), nil
+}
+
+func (a *Authenticator) userPrincipal(username string, account user) Principal {
+	return Principal{Name: username, Role: account.role, Method: "password", Scope: account.scope}
+}
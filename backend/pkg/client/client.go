@@ -0,0 +1,163 @@
+// Package client is a typed Go client for the versioned HTTP API, built on
+// the request and response types of the api package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"Docker_Management/pkg/api"
+)
+
+// Client calls the API of one backend.
+type Client struct {
+	BaseURL    string // e.g. http://localhost:8090
+	Token      string // Login or API token, sent as a bearer token
+	Host       string // Docker host to act on; the backend's default when empty
+	HTTPClient *http.Client
+}
+
+// New creates a client for the backend at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// Error is an error response from the API.
+type Error struct {
+	Status int // HTTP status code
+	api.ErrorResponse
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.Status, e.Code)
+}
+
+// request sends a request to path under the API prefix. A body that is not
+// an io.Reader is sent as JSON.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body interface{}, contentType string) (*http.Response, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.Host != "" && query.Get("host") == "" {
+		query.Set("host", c.Host)
+	}
+
+	target := c.BaseURL + api.APIPrefix + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = body
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+// do sends a request and decodes the JSON response into out, unless out is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.request(ctx, method, path, query, body, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// download sends a request and returns the response body, which the caller closes.
+func (c *Client) download(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.request(ctx, http.MethodGet, path, query, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stream opens a Server-Sent Events route, such as /events or
+// /containers/{id}/logs/stream, and returns the raw event stream. The stream
+// ends when ctx is cancelled or the caller closes it.
+func (c *Client) Stream(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	return c.download(ctx, path, query)
+}
+
+// decodeError reads the JSON error envelope of a failed response.
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{Status: resp.StatusCode}
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr.ErrorResponse); err != nil || apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// pathEscape escapes a path segment. Image references keep their slashes,
+// which the image routes accept.
+func pathEscape(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// cloneQuery copies query, so that parameters can be added without changing
+// the caller's values.
+func cloneQuery(query url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range query {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// overrideQuery asks a removal to override deletion protection.
+func overrideQuery(override api.ProtectionOverride) url.Values {
+	query := url.Values{}
+	if override.Override {
+		query.Set("override", "true")
+		query.Set("reason", override.Reason)
+	}
+	return query
+}
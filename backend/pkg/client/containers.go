@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+	"Docker_Management/pkg/docker"
+)
+
+// Containers pages through the running containers, or every container when
+// all is set. The query holds the filters, order and paging parameters of the
+// /containers route, and may be nil.
+func (c *Client) Containers(ctx context.Context, all bool, query url.Values) (api.ContainerPage, error) {
+	query = cloneQuery(query)
+	if all {
+		query.Set("all", "true")
+	}
+	var page api.ContainerPage
+	err := c.do(ctx, http.MethodGet, "/containers", query, nil, &page)
+	return page, err
+}
+
+// CreateContainer creates a container, pulling its image with the request's
+// credentials if it is missing.
+func (c *Client) CreateContainer(ctx context.Context, req api.CreateContainerRequest) (api.CreateContainerResponse, error) {
+	var response api.CreateContainerResponse
+	err := c.do(ctx, http.MethodPost, "/containers", nil, req, &response)
+	return response, err
+}
+
+// RunContainer creates a container and starts it.
+func (c *Client) RunContainer(ctx context.Context, req api.CreateContainerRequest) (api.CreateContainerResponse, error) {
+	var response api.CreateContainerResponse
+	err := c.do(ctx, http.MethodPost, "/containers/run", nil, req, &response)
+	return response, err
+}
+
+// RemoveAllContainers removes every container that is not protected.
+func (c *Client) RemoveAllContainers(ctx context.Context) (api.BulkRemoveResponse, error) {
+	var response api.BulkRemoveResponse
+	err := c.do(ctx, http.MethodDelete, "/containers", nil, nil, &response)
+	return response, err
+}
+
+// InspectContainer returns the full configuration and state of a container.
+func (c *Client) InspectContainer(ctx context.Context, id string) (api.InspectResponse, error) {
+	var response api.InspectResponse
+	err := c.do(ctx, http.MethodGet, "/containers/"+pathEscape(id), nil, nil, &response)
+	return response, err
+}
+
+// RemoveContainer removes a container.
+func (c *Client) RemoveContainer(ctx context.Context, id string, override api.ProtectionOverride) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodDelete, "/containers/"+pathEscape(id), overrideQuery(override), nil, &response)
+	return response, err
+}
+
+// StartContainer starts a container.
+func (c *Client) StartContainer(ctx context.Context, id string) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "start", api.ContainerActionRequest{ID: id})
+}
+
+// StopContainer stops a container.
+func (c *Client) StopContainer(ctx context.Context, id string) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "stop", api.ContainerActionRequest{ID: id})
+}
+
+// RestartContainer restarts the container req.ID, waiting req.Timeout for it to stop.
+func (c *Client) RestartContainer(ctx context.Context, req api.ContainerActionRequest) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "restart", req)
+}
+
+// PauseContainer pauses a container.
+func (c *Client) PauseContainer(ctx context.Context, id string) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "pause", api.ContainerActionRequest{ID: id})
+}
+
+// UnpauseContainer unpauses a container.
+func (c *Client) UnpauseContainer(ctx context.Context, id string) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "unpause", api.ContainerActionRequest{ID: id})
+}
+
+// KillContainer sends req.Signal to the container req.ID.
+func (c *Client) KillContainer(ctx context.Context, req api.ContainerActionRequest) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "kill", req)
+}
+
+// RenameContainer renames the container req.ID to req.Name.
+func (c *Client) RenameContainer(ctx context.Context, req api.ContainerActionRequest) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "rename", req)
+}
+
+// WaitContainer waits for the container req.ID to reach req.Condition.
+func (c *Client) WaitContainer(ctx context.Context, req api.ContainerActionRequest) (docker.ActionResult, error) {
+	return c.containerAction(ctx, "wait", req)
+}
+
+func (c *Client) containerAction(ctx context.Context, action string, req api.ContainerActionRequest) (docker.ActionResult, error) {
+	var result docker.ActionResult
+	err := c.do(ctx, http.MethodPost, "/containers/"+pathEscape(req.ID)+"/"+action, nil, req, &result)
+	return result, err
+}
+
+// CreateExec prepares a command to run in the container req.ID. The command
+// runs once a WebSocket client attaches to /exec/{exec}/attach.
+func (c *Client) CreateExec(ctx context.Context, req api.CreateExecRequest) (api.CreateExecResponse, error) {
+	var response api.CreateExecResponse
+	err := c.do(ctx, http.MethodPost, "/containers/"+pathEscape(req.ID)+"/exec", nil, req, &response)
+	return response, err
+}
+
+// ContainerLogs returns the logs of a container.
+func (c *Client) ContainerLogs(ctx context.Context, id string) (api.LogResponse, error) {
+	var response api.LogResponse
+	err := c.do(ctx, http.MethodGet, "/containers/"+pathEscape(id)+"/logs", nil, nil, &response)
+	return response, err
+}
+
+// ContainerStats returns the CPU and memory usage of a container.
+func (c *Client) ContainerStats(ctx context.Context, id string) (api.StatsResponse, error) {
+	var response api.StatsResponse
+	err := c.do(ctx, http.MethodGet, "/containers/"+pathEscape(id)+"/stats", nil, nil, &response)
+	return response, err
+}
+
+// ExportContainer returns the filesystem of a container as a tar stream,
+// which the caller closes.
+func (c *Client) ExportContainer(ctx context.Context, id string) (io.ReadCloser, error) {
+	return c.download(ctx, "/containers/"+pathEscape(id)+"/export", nil)
+}
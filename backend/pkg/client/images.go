@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+	"Docker_Management/pkg/docker"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Images pages through the images. The query holds the filters, order and
+// paging parameters of the /images route.
+func (c *Client) Images(ctx context.Context, query url.Values) (api.ImagePage, error) {
+	var page api.ImagePage
+	err := c.do(ctx, http.MethodGet, "/images", query, nil, &page)
+	return page, err
+}
+
+// DanglingImages pages through the untagged images. The query holds the
+// filters, order and paging parameters of the /images/dangling route.
+func (c *Client) DanglingImages(ctx context.Context, query url.Values) (api.DanglingImagePage, error) {
+	var page api.DanglingImagePage
+	err := c.do(ctx, http.MethodGet, "/images/dangling", query, nil, &page)
+	return page, err
+}
+
+// RemoveAllImages removes every image that is not protected.
+func (c *Client) RemoveAllImages(ctx context.Context) (api.BulkRemoveResponse, error) {
+	var response api.BulkRemoveResponse
+	err := c.do(ctx, http.MethodDelete, "/images", nil, nil, &response)
+	return response, err
+}
+
+// RemoveDanglingImages removes every untagged image that is not protected.
+func (c *Client) RemoveDanglingImages(ctx context.Context) (api.BulkRemoveResponse, error) {
+	var response api.BulkRemoveResponse
+	err := c.do(ctx, http.MethodDelete, "/images/dangling", nil, nil, &response)
+	return response, err
+}
+
+// ImageUsage breaks the disk usage of images down into shared and unique sizes.
+func (c *Client) ImageUsage(ctx context.Context) (docker.ImageUsageReport, error) {
+	var report docker.ImageUsageReport
+	err := c.do(ctx, http.MethodGet, "/images/usage", nil, nil, &report)
+	return report, err
+}
+
+// InspectImage returns the full details of an image.
+func (c *Client) InspectImage(ctx context.Context, ref string) (types.ImageInspect, error) {
+	var image types.ImageInspect
+	err := c.do(ctx, http.MethodGet, "/images/"+pathEscape(ref)+"/json", nil, nil, &image)
+	return image, err
+}
+
+// ImageHistory lists the layers of an image.
+func (c *Client) ImageHistory(ctx context.Context, ref string) ([]docker.ImageLayer, error) {
+	var layers []docker.ImageLayer
+	err := c.do(ctx, http.MethodGet, "/images/"+pathEscape(ref)+"/history", nil, nil, &layers)
+	return layers, err
+}
+
+// RemoveImage removes an image.
+func (c *Client) RemoveImage(ctx context.Context, ref string, override api.ProtectionOverride) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodDelete, "/images/"+pathEscape(ref), overrideQuery(override), nil, &response)
+	return response, err
+}
+
+// TagImage adds the reference target to the image source.
+func (c *Client) TagImage(ctx context.Context, source, target string) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodPost, "/images/"+pathEscape(source)+"/tag", nil, api.TagImageRequest{Source: source, Target: target}, &response)
+	return response, err
+}
+
+// UntagImage removes a reference from an image without deleting the image.
+func (c *Client) UntagImage(ctx context.Context, ref string) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodDelete, "/images/"+pathEscape(ref)+"/tag", nil, nil, &response)
+	return response, err
+}
+
+// PullImage pulls an image and waits for it.
+func (c *Client) PullImage(ctx context.Context, req api.PullImageRequest) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodPost, "/images/pull", nil, req, &response)
+	return response, err
+}
+
+// PullImageJob starts pulling an image in the background.
+func (c *Client) PullImageJob(ctx context.Context, req api.PullImageRequest) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodPost, "/images/pull/jobs", nil, req, &job)
+	return job, err
+}
+
+// PushImageJob starts pushing the image req.Image in the background.
+func (c *Client) PushImageJob(ctx context.Context, req api.PushImageRequest) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodPost, "/images/"+pathEscape(req.Image)+"/push/jobs", nil, req, &job)
+	return job, err
+}
+
+// MirrorImageJob starts copying an image between registries in the background.
+func (c *Client) MirrorImageJob(ctx context.Context, req api.MirrorImageRequest) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodPost, "/images/mirror/jobs", nil, req, &job)
+	return job, err
+}
+
+// BuildImage starts building an image from a context_path or git_url in the background.
+func (c *Client) BuildImage(ctx context.Context, spec docker.BuildSpec) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodPost, "/images/build", nil, spec, &job)
+	return job, err
+}
+
+// SaveImages returns the images as a docker-save tarball, which the caller closes.
+func (c *Client) SaveImages(ctx context.Context, images ...string) (io.ReadCloser, error) {
+	return c.download(ctx, "/images/save", url.Values{"image": images})
+}
+
+// LoadImages loads the images of a docker-save tarball.
+func (c *Client) LoadImages(ctx context.Context, tarball io.Reader) (api.LoadImagesResponse, error) {
+	var response api.LoadImagesResponse
+	err := c.upload(ctx, "/images/load", nil, tarball, &response)
+	return response, err
+}
+
+// ImportImage creates an image from a filesystem tarball.
+func (c *Client) ImportImage(ctx context.Context, tarball io.Reader, options docker.ImportOptions) (api.ImportImageResponse, error) {
+	query := url.Values{"change": options.Changes}
+	if options.Reference != "" {
+		query.Set("reference", options.Reference)
+	}
+	if options.Message != "" {
+		query.Set("message", options.Message)
+	}
+
+	var response api.ImportImageResponse
+	err := c.upload(ctx, "/images/import", query, tarball, &response)
+	return response, err
+}
+
+// upload posts a tarball and decodes the JSON response into out.
+func (c *Client) upload(ctx context.Context, path string, query url.Values, tarball io.Reader, out interface{}) error {
+	resp, err := c.request(ctx, http.MethodPost, path, query, tarball, "application/x-tar")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
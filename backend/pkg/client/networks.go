@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+)
+
+// Networks pages through the networks. The query holds the filters, order
+// and paging parameters of the /networks route.
+func (c *Client) Networks(ctx context.Context, query url.Values) (api.NetworkPage, error) {
+	var page api.NetworkPage
+	err := c.do(ctx, http.MethodGet, "/networks", query, nil, &page)
+	return page, err
+}
+
+// InspectNetwork returns the details of a network.
+func (c *Client) InspectNetwork(ctx context.Context, id string) (map[string]interface{}, error) {
+	var network map[string]interface{}
+	err := c.do(ctx, http.MethodGet, "/networks/"+pathEscape(id), nil, nil, &network)
+	return network, err
+}
+
+// NetworkContainers lists the containers attached to a network.
+func (c *Client) NetworkContainers(ctx context.Context, id string) (api.NetworkContainersResponse, error) {
+	var response api.NetworkContainersResponse
+	err := c.do(ctx, http.MethodGet, "/networks/"+pathEscape(id)+"/containers", nil, nil, &response)
+	return response, err
+}
+
+// RemoveNetwork removes a network.
+func (c *Client) RemoveNetwork(ctx context.Context, id string, override api.ProtectionOverride) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodDelete, "/networks/"+pathEscape(id), overrideQuery(override), nil, &response)
+	return response, err
+}
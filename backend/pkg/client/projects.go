@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+	"Docker_Management/pkg/docker"
+)
+
+// Projects lists the Docker Compose projects.
+func (c *Client) Projects(ctx context.Context) ([]api.ProjectResponse, error) {
+	var projects []api.ProjectResponse
+	err := c.do(ctx, http.MethodGet, "/projects", nil, nil, &projects)
+	return projects, err
+}
+
+// Project returns the services, networks and volumes of a Compose project.
+func (c *Client) Project(ctx context.Context, name string) (api.ProjectResponse, error) {
+	var project api.ProjectResponse
+	err := c.do(ctx, http.MethodGet, "/projects/"+pathEscape(name), nil, nil, &project)
+	return project, err
+}
+
+// ProjectLogs opens the log stream of a Compose project. The query holds the
+// parameters of the /projects/{project}/logs/stream route, and may be nil.
+func (c *Client) ProjectLogs(ctx context.Context, name string, query url.Values) (io.ReadCloser, error) {
+	return c.Stream(ctx, "/projects/"+pathEscape(name)+"/logs/stream", cloneQuery(query))
+}
+
+// StartProject starts the containers of the project req.Project, dependencies first.
+func (c *Client) StartProject(ctx context.Context, req api.ProjectActionRequest) (docker.ProjectActionResult, error) {
+	return c.projectAction(ctx, "start", req)
+}
+
+// StopProject stops the containers of the project req.Project, dependents first.
+func (c *Client) StopProject(ctx context.Context, req api.ProjectActionRequest) (docker.ProjectActionResult, error) {
+	return c.projectAction(ctx, "stop", req)
+}
+
+// RestartProject restarts the containers of the project req.Project, dependencies first.
+func (c *Client) RestartProject(ctx context.Context, req api.ProjectActionRequest) (docker.ProjectActionResult, error) {
+	return c.projectAction(ctx, "restart", req)
+}
+
+// DownProject removes the containers and networks of the project
+// req.Project, and its volumes when req.Volumes is set.
+func (c *Client) DownProject(ctx context.Context, req api.ProjectActionRequest) (docker.ProjectActionResult, error) {
+	return c.projectAction(ctx, "down", req)
+}
+
+func (c *Client) projectAction(ctx context.Context, action string, req api.ProjectActionRequest) (docker.ProjectActionResult, error) {
+	var result docker.ProjectActionResult
+	err := c.do(ctx, http.MethodPost, "/projects/"+pathEscape(req.Project)+"/"+action, nil, req, &result)
+	return result, err
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+	"Docker_Management/pkg/auth"
+	"Docker_Management/pkg/docker"
+)
+
+// Login exchanges a username and password for a login token. Set Token to
+// the returned token to authenticate later calls.
+func (c *Client) Login(ctx context.Context, username, password string) (api.LoginResponse, error) {
+	var response api.LoginResponse
+	err := c.do(ctx, http.MethodPost, "/auth/login", nil, api.LoginRequest{Username: username, Password: password}, &response)
+	return response, err
+}
+
+// WhoAmI returns the identity and role of the caller.
+func (c *Client) WhoAmI(ctx context.Context) (auth.Principal, error) {
+	var principal auth.Principal
+	err := c.do(ctx, http.MethodGet, "/auth/me", nil, nil, &principal)
+	return principal, err
+}
+
+// Hosts lists the registered Docker hosts.
+func (c *Client) Hosts(ctx context.Context) ([]docker.HostInfo, error) {
+	var hosts []docker.HostInfo
+	err := c.do(ctx, http.MethodGet, "/hosts", nil, nil, &hosts)
+	return hosts, err
+}
+
+// History pages through the lifecycle history. The query holds the filters
+// and paging parameters of the /history route.
+func (c *Client) History(ctx context.Context, query url.Values) (api.HistoryResponse, error) {
+	var response api.HistoryResponse
+	err := c.do(ctx, http.MethodGet, "/history", query, nil, &response)
+	return response, err
+}
+
+// Audit pages through the audit log. The query holds the filters and paging
+// parameters of the /audit route.
+func (c *Client) Audit(ctx context.Context, query url.Values) (api.AuditResponse, error) {
+	var response api.AuditResponse
+	err := c.do(ctx, http.MethodGet, "/audit", query, nil, &response)
+	return response, err
+}
+
+// Prune removes unused resources, or with DryRun reports what would be removed.
+func (c *Client) Prune(ctx context.Context, options docker.PruneOptions) (docker.PruneReport, error) {
+	var report docker.PruneReport
+	err := c.do(ctx, http.MethodPost, "/prune", nil, options, &report)
+	return report, err
+}
+
+// Jobs lists the background jobs, newest first.
+func (c *Client) Jobs(ctx context.Context) ([]docker.JobInfo, error) {
+	var jobs []docker.JobInfo
+	err := c.do(ctx, http.MethodGet, "/jobs", nil, nil, &jobs)
+	return jobs, err
+}
+
+// Job returns the state of a job.
+func (c *Client) Job(ctx context.Context, id string) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodGet, "/jobs/"+pathEscape(id), nil, nil, &job)
+	return job, err
+}
+
+// CancelJob cancels a running job.
+func (c *Client) CancelJob(ctx context.Context, id string) (docker.JobInfo, error) {
+	var job docker.JobInfo
+	err := c.do(ctx, http.MethodPost, "/jobs/"+pathEscape(id)+"/cancel", nil, nil, &job)
+	return job, err
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"Docker_Management/pkg/api"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Volumes pages through the volumes. The query holds the filters, order and
+// paging parameters of the /volumes route.
+func (c *Client) Volumes(ctx context.Context, query url.Values) (api.VolumePage, error) {
+	var page api.VolumePage
+	err := c.do(ctx, http.MethodGet, "/volumes", query, nil, &page)
+	return page, err
+}
+
+// InspectVolume returns the details of a volume.
+func (c *Client) InspectVolume(ctx context.Context, name string) (types.Volume, error) {
+	var volume types.Volume
+	err := c.do(ctx, http.MethodGet, "/volumes/"+pathEscape(name), nil, nil, &volume)
+	return volume, err
+}
+
+// VolumeContainers lists the containers using a volume.
+func (c *Client) VolumeContainers(ctx context.Context, name string) (api.ResponseVolumeContainers, error) {
+	var response api.ResponseVolumeContainers
+	err := c.do(ctx, http.MethodGet, "/volumes/"+pathEscape(name)+"/containers", nil, nil, &response)
+	return response, err
+}
+
+// RemoveVolume removes a volume.
+func (c *Client) RemoveVolume(ctx context.Context, name string, override api.ProtectionOverride) (api.MessageResponse, error) {
+	var response api.MessageResponse
+	err := c.do(ctx, http.MethodDelete, "/volumes/"+pathEscape(name), overrideQuery(override), nil, &response)
+	return response, err
+}
@@ -1,12 +1,98 @@
 package config
 
 import (
+	"encoding/json"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	MongoURI   string
-	ServerPort string
+	// MongoURI locates the history database; empty keeps history in memory
+	MongoURI string
+	// HistoryMaxRecords and HistoryMaxAge bound the in-memory history used without MongoDB
+	HistoryMaxRecords int
+	HistoryMaxAge     time.Duration
+	ServerPort        string
+	// DockerHosts lists every managed daemon; the first entry is the default host
+	DockerHosts []DockerConfig
+	// RegistryCredentials are the stored credential sets for private registries
+	RegistryCredentials []RegistryCredential
+	// ProtectionRules name the resources that must not be deleted without an override
+	ProtectionRules []ProtectionRule
+	// Auth holds the API users and tokens; with neither, the API is open
+	Auth AuthConfig
+	// AuditSink is where mutating API calls are logged: mongodb or file
+	AuditSink    string
+	AuditLogFile string // JSON lines file used by the file sink
+	// HTTP configures the middleware every request passes through
+	HTTP HTTPConfig
+}
+
+// HTTPConfig configures CORS, logging, compression and request limits.
+type HTTPConfig struct {
+	CORSAllowedOrigins []string // Origins allowed to call the API from a browser; * allows any, without credentials
+	CORSMaxAge         int      // Seconds a browser may cache a preflight response
+	AccessLog          bool     // Write a JSON line to stdout for every request
+	Gzip               bool     // Compress JSON and text responses for clients that accept it
+	MaxBodyBytes       int64    // Largest request body accepted
+	MaxUploadBytes     int64    // Largest tar upload accepted by build, load and import; 0 means no limit
+	BuildRoot          string   // Directory a build context_path must lie under; empty refuses context_path builds
+}
+
+// AuthConfig describes who may call the API.
+type AuthConfig struct {
+	JWTSecret string        `json:"-"` // Signs login tokens; a random secret is used if empty
+	TokenTTL  time.Duration `json:"-"` // Lifetime of login tokens
+	Users     []AuthUser    `json:"users"`
+	Tokens    []APIToken    `json:"tokens"`
+}
+
+// AuthUser is a local account that logs in with a username and password.
+type AuthUser struct {
+	Username     string   `json:"username"`
+	PasswordHash string   `json:"password_hash"` // bcrypt hash, e.g. from htpasswd -nbB
+	Role         string   `json:"role"`          // viewer, operator or admin
+	Scope        []string `json:"scope"`         // Optional label selectors (key or key=value) limiting the resources the user can reach
+}
+
+// APIToken is a static bearer token for scripts and other services.
+type APIToken struct {
+	Name        string   `json:"name"`
+	TokenSHA256 string   `json:"token_sha256"` // Hex SHA-256 of the token, so the token itself is not stored
+	Role        string   `json:"role"`
+	Scope       []string `json:"scope"`
+}
+
+// DockerConfig describes how to reach a Docker daemon.
+type DockerConfig struct {
+	Name       string `json:"name"`
+	Host       string `json:"host"`        // Daemon endpoint, e.g. unix:///var/run/docker.sock or tcp://host:2376
+	APIVersion string `json:"api_version"` // Pinned API version; empty means negotiate with the daemon
+	CertPath   string `json:"cert_path"`   // Directory holding ca.pem, cert.pem and key.pem
+	TLSVerify  bool   `json:"tls_verify"`
+}
+
+// RegistryCredential is a named set of credentials for a container registry.
+type RegistryCredential struct {
+	Name          string `json:"name"`
+	ServerAddress string `json:"server_address"` // e.g. registry.example.com or https://index.docker.io/v1/
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identity_token"` // Used instead of a password by token-based registries
+}
+
+// ProtectionRule marks resources as protected from deletion. A resource of one
+// of Types is protected if it matches any of the label selectors, name globs
+// or explicit resources.
+type ProtectionRule struct {
+	Name      string   `json:"name"`      // Reported when a deletion is refused
+	Types     []string `json:"types"`     // containers, images, volumes or networks; empty means all
+	Labels    []string `json:"labels"`    // key or key=value
+	Names     []string `json:"names"`     // Globs matched against names and image tags, e.g. postgres:*
+	Resources []string `json:"resources"` // Exact names, image tags or IDs
 }
 
 var AppConfig Config
@@ -19,9 +105,128 @@ func LoadConfig() {
 	// }
 
 	AppConfig = Config{
-		//MongoURI:   getEnv("MONGO_URI", "mongodb://localhost:27017"),
-		ServerPort: getEnv("SERVER_PORT", "8090"),
+		MongoURI:          getEnv("MONGO_URI", ""),
+		ServerPort:        getEnv("SERVER_PORT", "8090"),
+		HistoryMaxRecords: int(getEnvInt("HISTORY_MAX_RECORDS", 10000)),
+		HistoryMaxAge:     getEnvDuration("HISTORY_MAX_AGE", 30*24*time.Hour),
+		AuditSink:         getEnv("AUDIT_SINK", ""),
+		AuditLogFile:      getEnv("AUDIT_LOG_FILE", "audit.jsonl"),
+		HTTP: HTTPConfig{
+			CORSAllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:4200"}),
+			CORSMaxAge:         int(getEnvInt("CORS_MAX_AGE", 600)),
+			AccessLog:          getEnvBool("ACCESS_LOG", true),
+			Gzip:               getEnvBool("GZIP", true),
+			MaxBodyBytes:       getEnvInt("MAX_BODY_BYTES", 1<<20),
+			MaxUploadBytes:     getEnvInt("MAX_UPLOAD_BYTES", 0),
+			BuildRoot:          getEnv("BUILD_ROOT", ""),
+		},
+		DockerHosts: []DockerConfig{{
+			Name:       getEnv("DOCKER_HOST_NAME", "local"),
+			Host:       getEnv("DOCKER_HOST", ""),
+			APIVersion: getEnv("DOCKER_API_VERSION", ""),
+			CertPath:   getEnv("DOCKER_CERT_PATH", ""),
+			TLSVerify:  getEnvBool("DOCKER_TLS_VERIFY", false),
+		}},
+	}
+
+	// Audit to MongoDB when it is configured, otherwise to a local file
+	if AppConfig.AuditSink == "" {
+		AppConfig.AuditSink = "file"
+		if AppConfig.MongoURI != "" {
+			AppConfig.AuditSink = "mongodb"
+		}
+	}
+
+	// Append any additional hosts from the registry file
+	if path := getEnv("DOCKER_HOSTS_FILE", ""); path != "" {
+		hosts, err := loadDockerHosts(path)
+		if err != nil {
+			log.Fatalf("Failed to load Docker hosts from %s: %v", path, err)
+		}
+		AppConfig.DockerHosts = append(AppConfig.DockerHosts, hosts...)
+	}
+
+	// Load the stored registry credentials
+	if path := getEnv("REGISTRY_CREDENTIALS_FILE", ""); path != "" {
+		credentials, err := loadRegistryCredentials(path)
+		if err != nil {
+			log.Fatalf("Failed to load registry credentials from %s: %v", path, err)
+		}
+		AppConfig.RegistryCredentials = credentials
 	}
+
+	// Load the deletion protection rules
+	if path := getEnv("PROTECTION_RULES_FILE", ""); path != "" {
+		rules, err := loadProtectionRules(path)
+		if err != nil {
+			log.Fatalf("Failed to load protection rules from %s: %v", path, err)
+		}
+		AppConfig.ProtectionRules = rules
+	}
+
+	// Load the API users and tokens
+	AppConfig.Auth.JWTSecret = getEnv("JWT_SECRET", "")
+	AppConfig.Auth.TokenTTL = getEnvDuration("JWT_TTL", 12*time.Hour)
+	if path := getEnv("AUTH_FILE", ""); path != "" {
+		if err := loadAuthConfig(path, &AppConfig.Auth); err != nil {
+			log.Fatalf("Failed to load users and tokens from %s: %v", path, err)
+		}
+	}
+}
+
+// loadDockerHosts reads a JSON array of DockerConfig entries from path.
+func loadDockerHosts(path string) ([]DockerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []DockerConfig
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// loadRegistryCredentials reads a JSON array of RegistryCredential entries from path.
+func loadRegistryCredentials(path string) ([]RegistryCredential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials []RegistryCredential
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// loadProtectionRules reads a JSON array of ProtectionRule entries from path.
+func loadProtectionRules(path string) ([]ProtectionRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []ProtectionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// loadAuthConfig reads the users and tokens of auth from a JSON object at path.
+func loadAuthConfig(path string, auth *AuthConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, auth)
 }
 
 // getEnv retrieves the value of the environment variable or returns a fallback value if not set.
@@ -32,3 +237,57 @@ func getEnv(key string, fallback string) string {
 	}
 	return value
 }
+
+// getEnvBool retrieves a boolean environment variable or returns a fallback value if not set or invalid.
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvDuration retrieves a duration environment variable, such as 12h, or returns a fallback value if not set or invalid.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvInt retrieves an integer environment variable or returns a fallback value if not set or invalid.
+func getEnvInt(key string, fallback int64) int64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvList retrieves a comma-separated environment variable or returns a fallback value if not set.
+func getEnvList(key string, fallback []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
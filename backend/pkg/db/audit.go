@@ -0,0 +1,200 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"Docker_Management/pkg/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditStore persists the audit log of mutating API calls.
+type AuditStore interface {
+	Insert(ctx context.Context, entry models.AuditEntry) error
+	// Find returns the matching page of entries, newest first, and the total number of matches.
+	Find(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int64, error)
+}
+
+// MongoAuditStore stores audit entries in a MongoDB collection.
+type MongoAuditStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoAuditStore creates an AuditStore backed by collection.
+func NewMongoAuditStore(collection *mongo.Collection) *MongoAuditStore {
+	return &MongoAuditStore{collection: collection}
+}
+
+func (s *MongoAuditStore) Insert(ctx context.Context, entry models.AuditEntry) error {
+	_, err := s.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (s *MongoAuditStore) Find(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+	query := bson.M{}
+	addMatch := func(field, value string) {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	addMatch("principal", filter.Principal)
+	addMatch("method", filter.Method)
+	addMatch("route", filter.Route)
+	addMatch("host", filter.Host)
+	addMatch("outcome", filter.Outcome)
+	if filter.Target != "" {
+		// Allow short IDs; the match applies to any element of targets
+		query["targets"] = bson.M{"$regex": "^" + regexQuote(filter.Target)}
+	}
+
+	timeRange := bson.M{}
+	if !filter.Since.IsZero() {
+		timeRange["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		timeRange["$lte"] = filter.Until
+	}
+	if len(timeRange) > 0 {
+		query["timestamp"] = timeRange
+	}
+
+	total, err := s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(filter.Skip)
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// FileAuditStore appends audit entries to a file as JSON lines. Queries scan
+// the whole file, which suits the volume of a single backend.
+type FileAuditStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileAuditStore creates an AuditStore writing to path, creating it if needed.
+func NewFileAuditStore(path string) (*FileAuditStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return &FileAuditStore{path: path}, nil
+}
+
+func (s *FileAuditStore) Insert(ctx context.Context, entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *FileAuditStore) Find(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []models.AuditEntry{}, 0, nil
+		}
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var matches []models.AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip a line cut short by a crash
+			continue
+		}
+		if matchesAuditFilter(entry, filter) {
+			matches = append(matches, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// The file is in write order, so reverse it to put the newest first
+	for i, k := 0, len(matches)-1; i < k; i, k = i+1, k-1 {
+		matches[i], matches[k] = matches[k], matches[i]
+	}
+
+	total := int64(len(matches))
+	start := filter.Skip
+	if start > total {
+		start = total
+	}
+	end := total
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+
+	return append([]models.AuditEntry{}, matches[start:end]...), total, nil
+}
+
+func matchesAuditFilter(entry models.AuditEntry, filter models.AuditFilter) bool {
+	matches := func(value, want string) bool {
+		return want == "" || value == want
+	}
+
+	targetMatches := filter.Target == ""
+	for _, target := range entry.Targets {
+		if strings.HasPrefix(target, filter.Target) {
+			targetMatches = true
+		}
+	}
+
+	return matches(entry.Principal, filter.Principal) &&
+		matches(entry.Method, filter.Method) &&
+		matches(entry.Route, filter.Route) &&
+		matches(entry.Host, filter.Host) &&
+		matches(entry.Outcome, filter.Outcome) &&
+		targetMatches &&
+		(filter.Since.IsZero() || !entry.Timestamp.Before(filter.Since)) &&
+		(filter.Until.IsZero() || !entry.Timestamp.After(filter.Until))
+}
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Docker_Management/pkg/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HistoryStore persists container lifecycle history.
+type HistoryStore interface {
+	Insert(ctx context.Context, record models.HistoryRecord) error
+	// Find returns the matching page of records, newest first, and the total number of matches.
+	Find(ctx context.Context, filter models.HistoryFilter) ([]models.HistoryRecord, int64, error)
+}
+
+// MongoHistoryStore stores history records in a MongoDB collection.
+type MongoHistoryStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoHistoryStore creates a HistoryStore backed by collection.
+func NewMongoHistoryStore(collection *mongo.Collection) *MongoHistoryStore {
+	return &MongoHistoryStore{collection: collection}
+}
+
+func (s *MongoHistoryStore) Insert(ctx context.Context, record models.HistoryRecord) error {
+	_, err := s.collection.InsertOne(ctx, record)
+	return err
+}
+
+func (s *MongoHistoryStore) Find(ctx context.Context, filter models.HistoryFilter) ([]models.HistoryRecord, int64, error) {
+	query := bson.M{}
+	addMatch := func(field, value string) {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	addMatch("host", filter.Host)
+	addMatch("action", filter.Action)
+	addMatch("image", filter.Image)
+	addMatch("actor", filter.Actor)
+	addMatch("source", filter.Source)
+	addMatch("outcome", filter.Outcome)
+	if filter.ContainerID != "" {
+		// Allow short container IDs
+		query["container_id"] = bson.M{"$regex": "^" + regexQuote(filter.ContainerID)}
+	}
+
+	timeRange := bson.M{}
+	if !filter.Since.IsZero() {
+		timeRange["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		timeRange["$lte"] = filter.Until
+	}
+	if len(timeRange) > 0 {
+		query["timestamp"] = timeRange
+	}
+
+	total, err := s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(filter.Skip)
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.HistoryRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// regexQuote escapes regular expression metacharacters in s.
+func regexQuote(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MemoryHistoryStore keeps history records in memory. It is used when no
+// MongoDB is configured and as a stand-in for the database in tests. Only the
+// newest maxRecords records younger than maxAge are kept, so a long-running
+// server does not grow without bound.
+type MemoryHistoryStore struct {
+	mu         sync.Mutex
+	records    []models.HistoryRecord // Sorted by timestamp, oldest first
+	maxRecords int                    // 0 means no limit
+	maxAge     time.Duration          // 0 means records never expire
+}
+
+// NewMemoryHistoryStore creates an empty in-memory HistoryStore holding at
+// most maxRecords records, each for at most maxAge. Zero disables either limit.
+func NewMemoryHistoryStore(maxRecords int, maxAge time.Duration) *MemoryHistoryStore {
+	return &MemoryHistoryStore{maxRecords: maxRecords, maxAge: maxAge}
+}
+
+func (s *MemoryHistoryStore) Insert(ctx context.Context, record models.HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Daemon events carry their own timestamps and can arrive late, so keep
+	// the records sorted rather than in arrival order
+	i := sort.Search(len(s.records), func(i int) bool {
+		return s.records[i].Timestamp.After(record.Timestamp)
+	})
+	s.records = append(s.records, models.HistoryRecord{})
+	copy(s.records[i+1:], s.records[i:])
+	s.records[i] = record
+	s.expire()
+	return nil
+}
+
+// expire drops the oldest records beyond maxRecords and those older than
+// maxAge. The records are sorted, so both are a prefix of s.records.
+func (s *MemoryHistoryStore) expire() {
+	drop := 0
+	if s.maxRecords > 0 && len(s.records) > s.maxRecords {
+		drop = len(s.records) - s.maxRecords
+	}
+	if s.maxAge > 0 {
+		cutoff := time.Now().Add(-s.maxAge)
+		for drop < len(s.records) && s.records[drop].Timestamp.Before(cutoff) {
+			drop++
+		}
+	}
+	// The next append that outgrows the array copies only the survivors,
+	// so the dropped records are freed then
+	s.records = s.records[drop:]
+}
+
+func (s *MemoryHistoryStore) Find(ctx context.Context, filter models.HistoryFilter) ([]models.HistoryRecord, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+
+	// Walk backwards so the newest records come first
+	var matches []models.HistoryRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if matchesHistoryFilter(s.records[i], filter) {
+			matches = append(matches, s.records[i])
+		}
+	}
+
+	total := int64(len(matches))
+	start := filter.Skip
+	if start > total {
+		start = total
+	}
+	end := total
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+
+	return append([]models.HistoryRecord{}, matches[start:end]...), total, nil
+}
+
+func matchesHistoryFilter(record models.HistoryRecord, filter models.HistoryFilter) bool {
+	matches := func(value, want string) bool {
+		return want == "" || value == want
+	}
+
+	return matches(record.Host, filter.Host) &&
+		matches(record.Action, filter.Action) &&
+		matches(record.Image, filter.Image) &&
+		matches(record.Actor, filter.Actor) &&
+		matches(record.Source, filter.Source) &&
+		matches(record.Outcome, filter.Outcome) &&
+		strings.HasPrefix(record.ContainerID, filter.ContainerID) &&
+		(filter.Since.IsZero() || !record.Timestamp.Before(filter.Since)) &&
+		(filter.Until.IsZero() || !record.Timestamp.After(filter.Until))
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"Docker_Management/pkg/models"
+)
+
+// insertHistory adds records one second apart, ending now, with the given
+// actions in order.
+func insertHistory(t *testing.T, store *MemoryHistoryStore, actions ...string) {
+	t.Helper()
+	start := time.Now().Add(-time.Duration(len(actions)) * time.Second)
+	for i, action := range actions {
+		record := models.HistoryRecord{
+			Host:        "local",
+			Action:      action,
+			ContainerID: fmt.Sprintf("c%02d", i),
+			Timestamp:   start.Add(time.Duration(i) * time.Second),
+		}
+		if err := store.Insert(context.Background(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func containerIDs(records []models.HistoryRecord) []string {
+	ids := []string{}
+	for _, record := range records {
+		ids = append(ids, record.ContainerID)
+	}
+	return ids
+}
+
+func TestMemoryHistoryStoreFind(t *testing.T) {
+	store := NewMemoryHistoryStore(0, 0)
+	insertHistory(t, store, "start", "stop", "start", "remove", "start")
+
+	tests := []struct {
+		name   string
+		filter models.HistoryFilter
+		want   []string
+		total  int64
+	}{
+		{"all newest first", models.HistoryFilter{}, []string{"c04", "c03", "c02", "c01", "c00"}, 5},
+		{"by action", models.HistoryFilter{Action: "start"}, []string{"c04", "c02", "c00"}, 3},
+		{"by container prefix", models.HistoryFilter{ContainerID: "c0", Action: "stop"}, []string{"c01"}, 1},
+		{"no match", models.HistoryFilter{Host: "remote"}, []string{}, 0},
+		{"first page", models.HistoryFilter{Limit: 2}, []string{"c04", "c03"}, 5},
+		{"second page", models.HistoryFilter{Skip: 2, Limit: 2}, []string{"c02", "c01"}, 5},
+		{"past the end", models.HistoryFilter{Skip: 10, Limit: 2}, []string{}, 5},
+	}
+	for _, test := range tests {
+		records, total, err := store.Find(context.Background(), test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := containerIDs(records); fmt.Sprint(got) != fmt.Sprint(test.want) || total != test.total {
+			t.Errorf("%s: got %v of %d, want %v of %d", test.name, got, total, test.want, test.total)
+		}
+	}
+}
+
+func TestMemoryHistoryStoreKeepsNewestRecords(t *testing.T) {
+	store := NewMemoryHistoryStore(3, 0)
+	insertHistory(t, store, "start", "stop", "start", "stop", "start")
+
+	records, total, err := store.Find(context.Background(), models.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := containerIDs(records); fmt.Sprint(got) != "[c04 c03 c02]" || total != 3 {
+		t.Errorf("got %v of %d, want the newest 3", got, total)
+	}
+}
+
+func TestMemoryHistoryStoreExpiresOldRecords(t *testing.T) {
+	store := NewMemoryHistoryStore(0, time.Hour)
+	for i, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute} {
+		record := models.HistoryRecord{ContainerID: fmt.Sprintf("c%02d", i), Timestamp: time.Now().Add(-age)}
+		if err := store.Insert(context.Background(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, total, err := store.Find(context.Background(), models.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := containerIDs(records); fmt.Sprint(got) != "[c02]" || total != 1 {
+		t.Errorf("got %v of %d, want only the record younger than an hour", got, total)
+	}
+}
+
+func TestMemoryHistoryStoreOrdersLateRecords(t *testing.T) {
+	store := NewMemoryHistoryStore(3, time.Hour)
+	now := time.Now()
+	// Daemon events can be inserted after newer records
+	for _, record := range []models.HistoryRecord{
+		{ContainerID: "c5", Timestamp: now.Add(-1 * time.Minute)},
+		{ContainerID: "c1", Timestamp: now.Add(-5 * time.Minute)},
+		{ContainerID: "c4", Timestamp: now.Add(-2 * time.Minute)},
+		{ContainerID: "old", Timestamp: now.Add(-2 * time.Hour)},
+		{ContainerID: "c2", Timestamp: now.Add(-4 * time.Minute)},
+		{ContainerID: "c3", Timestamp: now.Add(-3 * time.Minute)},
+	} {
+		if err := store.Insert(context.Background(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, total, err := store.Find(context.Background(), models.HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := containerIDs(records); fmt.Sprint(got) != "[c5 c4 c3]" || total != 3 {
+		t.Errorf("got %v of %d, want the newest 3 by timestamp", got, total)
+	}
+}
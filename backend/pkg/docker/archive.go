@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// SaveImages writes the given images to w as a docker-save tarball.
+func (s *DockerService) SaveImages(ctx context.Context, images []string, w io.Writer) error {
+	reader, err := s.cli.ImageSave(ctx, images)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// CheckImagesExist reports the first of images that is not available locally.
+// It lets callers fail cleanly before they start streaming a tarball.
+func (s *DockerService) CheckImagesExist(ctx context.Context, images []string) error {
+	for _, image := range images {
+		if _, _, err := s.cli.ImageInspectWithRaw(ctx, image); err != nil {
+			return notFound(err, "image", image)
+		}
+	}
+	return nil
+}
+
+// LoadImages loads a docker-save tarball and returns the loaded image tags,
+// or image IDs for untagged images.
+func (s *DockerService) LoadImages(ctx context.Context, tarball io.Reader) ([]string, error) {
+	response, err := s.cli.ImageLoad(ctx, tarball, true)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	// Each loaded image is reported as "Loaded image: <tag>" or "Loaded image ID: <id>"
+	loaded := []string{}
+	err = decodeProgress(response.Body, func(message jsonmessage.JSONMessage) {
+		line := strings.TrimSpace(message.Stream)
+		for _, prefix := range []string{"Loaded image ID: ", "Loaded image: "} {
+			if strings.HasPrefix(line, prefix) {
+				loaded = append(loaded, strings.TrimPrefix(line, prefix))
+				break
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+// ExportContainer writes the filesystem of a container to w as a tar archive.
+func (s *DockerService) ExportContainer(ctx context.Context, containerID string, w io.Writer) error {
+	reader, err := s.cli.ContainerExport(ctx, containerID)
+	if err != nil {
+		return notFound(err, "container", containerID)
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// ImportOptions describes the image created from a filesystem tarball.
+type ImportOptions struct {
+	Reference string   // Repository and optional tag for the new image
+	Message   string   // Commit message
+	Changes   []string // Dockerfile instructions to apply, e.g. CMD ["/app"]
+}
+
+// ImportImage creates an image from a filesystem tarball, such as one written
+// by ExportContainer, and returns the new image ID.
+func (s *DockerService) ImportImage(ctx context.Context, tarball io.Reader, opts ImportOptions) (string, error) {
+	reader, err := s.cli.ImageImport(ctx, types.ImageImportSource{Source: tarball, SourceName: "-"}, opts.Reference, types.ImageImportOptions{
+		Message: opts.Message,
+		Changes: opts.Changes,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	// The final status message holds the new image ID
+	var imageID string
+	err = decodeProgress(reader, func(message jsonmessage.JSONMessage) {
+		if strings.HasPrefix(message.Status, "sha256:") {
+			imageID = message.Status
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return imageID, nil
+}
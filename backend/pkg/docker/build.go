@@ -0,0 +1,176 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// BuildSpec describes an image build. The build context is either uploaded by
+// the caller, read from ContextPath on the backend host, or fetched by the
+// daemon from GitURL.
+type BuildSpec struct {
+	ContextPath string            `json:"context_path"`
+	GitURL      string            `json:"git_url"` // e.g. https://github.com/org/repo.git#branch:subdir
+	Dockerfile  string            `json:"dockerfile"`
+	BuildArgs   map[string]string `json:"build_args"`
+	Target      string            `json:"target"`
+	Tags        []string          `json:"tags"`
+	Labels      map[string]string `json:"labels"`
+	NoCache     bool              `json:"no_cache"`
+	Pull        bool              `json:"pull"` // Always attempt to pull newer base images
+}
+
+// BuildImage builds an image and returns its ID. buildContext is a tar
+// archive; when it is nil the context comes from spec.GitURL or spec.ContextPath.
+// Each line of build output is passed to progress if set.
+func (s *DockerService) BuildImage(ctx context.Context, buildContext io.Reader, spec BuildSpec, progress func(jsonmessage.JSONMessage)) (string, error) {
+	buildArgs := map[string]*string{}
+	for key, value := range spec.BuildArgs {
+		value := value
+		buildArgs[key] = &value
+	}
+
+	options := types.ImageBuildOptions{
+		Dockerfile:  spec.Dockerfile,
+		BuildArgs:   buildArgs,
+		Target:      spec.Target,
+		Tags:        spec.Tags,
+		Labels:      spec.Labels,
+		NoCache:     spec.NoCache,
+		PullParent:  spec.Pull,
+		Remove:      true,
+		ForceRemove: true,
+	}
+
+	switch {
+	case buildContext != nil:
+	case spec.GitURL != "":
+		options.RemoteContext = spec.GitURL
+	case spec.ContextPath != "":
+		archive, err := tarDirectory(spec.ContextPath)
+		if err != nil {
+			return "", err
+		}
+		defer archive.Close()
+		buildContext = archive
+	default:
+		return "", invalidInput("a build context upload, context path or Git URL is required")
+	}
+
+	response, err := s.cli.ImageBuild(ctx, buildContext, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to build image: %w", Classify(err))
+	}
+	defer response.Body.Close()
+
+	// The daemon reports the new image ID as auxiliary data
+	var imageID string
+	err = decodeProgress(response.Body, func(message jsonmessage.JSONMessage) {
+		if message.Aux != nil {
+			var aux types.BuildResult
+			if json.Unmarshal(*message.Aux, &aux) == nil && aux.ID != "" {
+				imageID = aux.ID
+			}
+		}
+		if progress != nil {
+			progress(message)
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build image: %w", Classify(err))
+	}
+
+	return imageID, nil
+}
+
+// ResolveBuildContext confines a context path to root. A relative path is
+// taken from root, and symlinks are resolved before the check, so neither
+// ".." nor a link can reach outside it. The resolved path is returned.
+func ResolveBuildContext(root, path string) (string, error) {
+	if root == "" {
+		return "", invalidInput("context_path builds are disabled; upload the build context or use git_url")
+	}
+	root, err := filepath.EvalSymlinks(filepath.Clean(root))
+	if err != nil {
+		return "", fmt.Errorf("invalid build root: %v", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", invalidInput("invalid build context %s: %v", path, err)
+	}
+
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", invalidInput("build context %s is outside the build root", path)
+	}
+	return resolved, nil
+}
+
+// tarDirectory streams the contents of dir as a tar archive.
+func tarDirectory(dir string) (io.ReadCloser, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, invalidInput("build context %s is not a directory", dir)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(dir, path)
+			if err != nil || name == "." {
+				return err
+			}
+
+			var link string
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(name)
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tw, file)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	return reader, nil
+}
@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveBuildContext(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "builds")
+	for _, dir := range []string{filepath.Join(root, "app"), filepath.Join(base, "secret")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The temporary directory may itself sit behind a symlink
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(base, "secret"), filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"app", filepath.Join(root, "app"), filepath.Join(root, "app", "..", "app")} {
+		resolved, err := ResolveBuildContext(root, path)
+		if err != nil || resolved != filepath.Join(root, "app") {
+			t.Errorf("ResolveBuildContext(%q) = %q, %v; want %q", path, resolved, err, filepath.Join(root, "app"))
+		}
+	}
+
+	refused := map[string]string{
+		"parent":       "..",
+		"sibling":      filepath.Join(root, "..", "secret"),
+		"symlink":      "escape",
+		"prefix":       root + "-other",
+		"missing":      "nothing",
+		"absolute out": "/etc",
+	}
+	for name, path := range refused {
+		var invalid *InvalidInputError
+		if _, err := ResolveBuildContext(root, path); !errors.As(err, &invalid) {
+			t.Errorf("%s: ResolveBuildContext(%q) error = %v, want InvalidInputError", name, path, err)
+		}
+	}
+
+	var invalid *InvalidInputError
+	if _, err := ResolveBuildContext("", filepath.Join(root, "app")); !errors.As(err, &invalid) {
+		t.Errorf("without a build root: error = %v, want InvalidInputError", err)
+	}
+}
@@ -0,0 +1,444 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Labels Docker Compose puts on the containers, networks and volumes it creates
+const (
+	ComposeProjectLabel    = "com.docker.compose.project"
+	ComposeServiceLabel    = "com.docker.compose.service"
+	ComposeDependsOnLabel  = "com.docker.compose.depends_on" // e.g. db:service_healthy:false,cache:service_started:false
+	ComposeOneoffLabel     = "com.docker.compose.oneoff"     // True on containers of docker compose run
+	ComposeWorkingDirLabel = "com.docker.compose.project.working_dir"
+)
+
+// Project and service statuses
+const (
+	ProjectRunning = "running" // Every container is running
+	ProjectPartial = "partial" // Some containers are running
+	ProjectStopped = "stopped" // No container is running
+)
+
+// Outcomes of a project action step, besides the container result codes
+const (
+	ResultRemoved ResultCode = "removed"
+	ResultSkipped ResultCode = "skipped" // A service it depends on failed, or it is protected
+	ResultFailed  ResultCode = "failed"
+)
+
+// Project is a Docker Compose project: the containers, networks and volumes
+// carrying its project label.
+type Project struct {
+	Name       string           `json:"name"`
+	Status     string           `json:"status"`
+	Running    int              `json:"running"` // Running containers
+	Total      int              `json:"total"`   // Containers in every state
+	WorkingDir string           `json:"working_dir,omitempty"`
+	Services   []ProjectService `json:"services"` // Dependencies first
+	Networks   []string         `json:"networks"`
+	Volumes    []string         `json:"volumes"`
+}
+
+// ProjectService is a service of a project and the containers running it.
+type ProjectService struct {
+	Name       string             `json:"name"`
+	Status     string             `json:"status"`
+	DependsOn  []string           `json:"depends_on"`
+	Containers []ProjectContainer `json:"containers"`
+}
+
+// ProjectContainer is a container of a project service.
+type ProjectContainer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	State  string `json:"state"`  // e.g. running or exited
+	Status string `json:"status"` // e.g. Up 2 hours
+
+	labels map[string]string // Checked against the protection rules by down
+}
+
+// ProjectActionOptions controls a project lifecycle action.
+type ProjectActionOptions struct {
+	Timeout       *time.Duration    // Grace period before stopped containers are killed; nil uses their own
+	RemoveVolumes bool              // down also removes the project's volumes
+	Protection    *ProtectionPolicy // Policy the removals of down respect
+}
+
+// ProjectStep is the outcome of an action on one resource of a project.
+type ProjectStep struct {
+	Resource string     `json:"resource"` // container, network or volume
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Service  string     `json:"service,omitempty"`
+	Image    string     `json:"image,omitempty"`
+	Code     ResultCode `json:"code"`
+	Message  string     `json:"message"`
+	Failed   bool       `json:"failed"`
+}
+
+// ProjectActionResult reports a lifecycle action applied across a project,
+// step by step in the order the steps were taken.
+type ProjectActionResult struct {
+	Project string        `json:"project"`
+	Action  string        `json:"action"`
+	Failed  int           `json:"failed"` // Steps that failed
+	Steps   []ProjectStep `json:"steps"`
+}
+
+// projectFilter selects the resources of every project, or of the named one.
+func projectFilter(name string) filters.Args {
+	if name == "" {
+		return filters.NewArgs(filters.Arg("label", ComposeProjectLabel))
+	}
+	return filters.NewArgs(filters.Arg("label", ComposeProjectLabel+"="+name))
+}
+
+// ListProjects lists the Compose projects with containers on this host.
+// Containers for which inScope is false are left out, along with projects
+// left empty; a nil inScope keeps every container.
+func (s *DockerService) ListProjects(ctx context.Context, inScope func(labels map[string]string) bool) ([]Project, error) {
+	return s.projects(ctx, "", inScope)
+}
+
+// InspectProject returns the named Compose project.
+func (s *DockerService) InspectProject(ctx context.Context, name string, inScope func(labels map[string]string) bool) (Project, error) {
+	if name == "" {
+		return Project{}, invalidInput("a project name is required")
+	}
+	projects, err := s.projects(ctx, name, inScope)
+	if err != nil {
+		return Project{}, err
+	}
+	if len(projects) == 0 {
+		return Project{}, &NotFoundError{Kind: "project", ID: name}
+	}
+	return projects[0], nil
+}
+
+func (s *DockerService) projects(ctx context.Context, name string, inScope func(labels map[string]string) bool) ([]Project, error) {
+	filter := projectFilter(name)
+	containers, err := s.cli.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, Classify(err)
+	}
+	networks, err := s.cli.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return nil, Classify(err)
+	}
+	volumes, err := s.cli.VolumeList(ctx, filter)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	// Group the containers by project and service
+	byName := map[string]*Project{}
+	services := map[string]map[string]*ProjectService{}
+	for _, container := range containers {
+		if container.Labels[ComposeOneoffLabel] == "True" || (inScope != nil && !inScope(container.Labels)) {
+			continue
+		}
+		projectName := container.Labels[ComposeProjectLabel]
+		project, ok := byName[projectName]
+		if !ok {
+			project = &Project{Name: projectName, WorkingDir: container.Labels[ComposeWorkingDirLabel], Networks: []string{}, Volumes: []string{}}
+			byName[projectName] = project
+			services[projectName] = map[string]*ProjectService{}
+		}
+
+		serviceName := container.Labels[ComposeServiceLabel]
+		service, ok := services[projectName][serviceName]
+		if !ok {
+			service = &ProjectService{Name: serviceName, DependsOn: parseDependsOn(container.Labels[ComposeDependsOnLabel])}
+			services[projectName][serviceName] = service
+		}
+		service.Containers = append(service.Containers, ProjectContainer{
+			ID:     container.ID,
+			Name:   strings.TrimPrefix(firstName(container.Names), "/"),
+			Image:  container.Image,
+			State:  container.State,
+			Status: container.Status,
+			labels: container.Labels,
+		})
+	}
+
+	for _, network := range networks {
+		if project, ok := byName[network.Labels[ComposeProjectLabel]]; ok {
+			project.Networks = append(project.Networks, network.Name)
+		}
+	}
+	if volumes.Volumes != nil {
+		for _, volume := range volumes.Volumes {
+			if project, ok := byName[volume.Labels[ComposeProjectLabel]]; ok {
+				project.Volumes = append(project.Volumes, volume.Name)
+			}
+		}
+	}
+
+	projects := []Project{}
+	for projectName, project := range byName {
+		var list []ProjectService
+		for _, service := range services[projectName] {
+			running := 0
+			for _, container := range service.Containers {
+				if container.State == "running" {
+					running++
+				}
+			}
+			sort.Slice(service.Containers, func(i, j int) bool { return service.Containers[i].Name < service.Containers[j].Name })
+			service.Status = projectStatus(running, len(service.Containers))
+			project.Running += running
+			project.Total += len(service.Containers)
+			list = append(list, *service)
+		}
+		project.Services = serviceOrder(list)
+		project.Status = projectStatus(project.Running, project.Total)
+		sort.Strings(project.Networks)
+		sort.Strings(project.Volumes)
+		projects = append(projects, *project)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+func firstName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+func projectStatus(running, total int) string {
+	switch {
+	case running == 0:
+		return ProjectStopped
+	case running < total:
+		return ProjectPartial
+	}
+	return ProjectRunning
+}
+
+// parseDependsOn returns the services named by a depends_on label, whose
+// entries are service:condition:restart or, from older Compose, service alone.
+func parseDependsOn(label string) []string {
+	dependsOn := []string{}
+	for _, entry := range strings.Split(label, ",") {
+		if service := strings.TrimSpace(strings.SplitN(entry, ":", 2)[0]); service != "" {
+			dependsOn = append(dependsOn, service)
+		}
+	}
+	sort.Strings(dependsOn)
+	return dependsOn
+}
+
+// serviceOrder sorts services so that each comes after the services it
+// depends on, and otherwise by name. Dependencies outside the project are
+// ignored, and services caught in a cycle come last.
+func serviceOrder(services []ProjectService) []ProjectService {
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	known := map[string]bool{}
+	for _, service := range services {
+		known[service.Name] = true
+	}
+
+	ordered := make([]ProjectService, 0, len(services))
+	placed := map[string]bool{}
+	for len(ordered) < len(services) {
+		progress := false
+		for _, service := range services {
+			if placed[service.Name] || !dependenciesPlaced(service, known, placed) {
+				continue
+			}
+			ordered = append(ordered, service)
+			placed[service.Name] = true
+			progress = true
+		}
+		if !progress {
+			for _, service := range services {
+				if !placed[service.Name] {
+					ordered = append(ordered, service)
+				}
+			}
+			break
+		}
+	}
+	return ordered
+}
+
+func dependenciesPlaced(service ProjectService, known, placed map[string]bool) bool {
+	for _, dependency := range service.DependsOn {
+		if known[dependency] && !placed[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+// StartProject starts the containers of a project, dependencies first. The
+// services depending on one that failed to start are skipped.
+func (s *DockerService) StartProject(ctx context.Context, name string) (ProjectActionResult, error) {
+	return s.forwardProjectAction(ctx, name, "start", ResultAlreadyRunning, func(container ProjectContainer) (ActionResult, error) {
+		return s.StartContainer(ctx, container.ID)
+	})
+}
+
+// RestartProject restarts the containers of a project, dependencies first.
+// The services depending on one that failed to restart are skipped.
+func (s *DockerService) RestartProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error) {
+	return s.forwardProjectAction(ctx, name, "restart", "", func(container ProjectContainer) (ActionResult, error) {
+		return s.RestartContainer(ctx, container.ID, opts.Timeout)
+	})
+}
+
+// StopProject stops the containers of a project, dependents first.
+func (s *DockerService) StopProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error) {
+	project, err := s.InspectProject(ctx, name, nil)
+	if err != nil {
+		return ProjectActionResult{}, err
+	}
+
+	result := ProjectActionResult{Project: project.Name, Action: "stop", Steps: []ProjectStep{}}
+	for i := len(project.Services) - 1; i >= 0; i-- {
+		service := project.Services[i]
+		for _, container := range service.Containers {
+			actionResult, err := s.StopContainer(ctx, container.ID, opts.Timeout)
+			result.add(containerStep(service.Name, container, actionResult, err, ResultNotRunning))
+		}
+	}
+	return result, nil
+}
+
+// DownProject stops and removes the containers of a project, dependents
+// first, then removes its networks and, with RemoveVolumes, its volumes.
+// Protected containers are left running and reported as skipped steps;
+// protected networks and volumes are left in place and reported as failed.
+func (s *DockerService) DownProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error) {
+	project, err := s.InspectProject(ctx, name, nil)
+	if err != nil {
+		return ProjectActionResult{}, err
+	}
+
+	result := ProjectActionResult{Project: project.Name, Action: "down", Steps: []ProjectStep{}}
+	for i := len(project.Services) - 1; i >= 0; i-- {
+		service := project.Services[i]
+		for _, container := range service.Containers {
+			// Check before stopping, since down must not take a protected container offline
+			resource := Resource{Type: PruneContainers, ID: container.ID, Names: []string{container.Name}, Labels: container.labels}
+			if err := opts.Protection.Check(resource); err != nil {
+				result.add(containerStep(service.Name, container, newActionResult(ResultSkipped, "Skipped, "+err.Error()), nil, ""))
+				continue
+			}
+
+			// Stop first, so the container gets its grace period before removal
+			_, err := s.StopContainer(ctx, container.ID, opts.Timeout)
+			message := ""
+			if err == nil || isState(err, ResultNotRunning) {
+				message, err = s.RemoveContainer(ctx, container.ID, opts.Protection)
+			}
+			result.add(containerStep(service.Name, container, newActionResult(ResultRemoved, message), err, ""))
+		}
+	}
+
+	for _, network := range project.Networks {
+		message, err := s.RemoveNetwork(ctx, network, opts.Protection)
+		result.add(removalStep("network", network, message, err))
+	}
+	if opts.RemoveVolumes {
+		for _, volume := range project.Volumes {
+			message, err := s.RemoveVolume(ctx, volume, opts.Protection)
+			result.add(removalStep("volume", volume, message, err))
+		}
+	}
+	return result, nil
+}
+
+// forwardProjectAction applies apply to the containers of a project in
+// dependency order, skipping the services that depend on a failed one. A
+// container already in the state accepted names is not a failure.
+func (s *DockerService) forwardProjectAction(ctx context.Context, name, action string, accepted ResultCode, apply func(ProjectContainer) (ActionResult, error)) (ProjectActionResult, error) {
+	project, err := s.InspectProject(ctx, name, nil)
+	if err != nil {
+		return ProjectActionResult{}, err
+	}
+
+	result := ProjectActionResult{Project: project.Name, Action: action, Steps: []ProjectStep{}}
+	failed := map[string]bool{}
+	for _, service := range project.Services {
+		var failedDependency string
+		for _, dependency := range service.DependsOn {
+			if failed[dependency] {
+				failedDependency = dependency
+				break
+			}
+		}
+
+		for _, container := range service.Containers {
+			if failedDependency != "" {
+				result.add(containerStep(service.Name, container, newActionResult(ResultSkipped, fmt.Sprintf("Skipped, service %s did not %s", failedDependency, action)), nil, ""))
+				failed[service.Name] = true
+				continue
+			}
+
+			actionResult, err := apply(container)
+			step := containerStep(service.Name, container, actionResult, err, accepted)
+			// A container that exits straight away fails its dependents too
+			if step.Failed || step.Code == ResultExited {
+				failed[service.Name] = true
+			}
+			result.add(step)
+		}
+	}
+	return result, nil
+}
+
+// isState reports whether err refused an action because the container was
+// in the state code describes.
+func isState(err error, code ResultCode) bool {
+	var stateErr *StateError
+	return errors.As(err, &stateErr) && stateErr.Code == code
+}
+
+func (s *ProjectActionResult) add(step ProjectStep) {
+	if step.Failed {
+		s.Failed++
+	}
+	s.Steps = append(s.Steps, step)
+}
+
+// containerStep reports the outcome of an action on a container. A refusal
+// because the container already is in the accepted state is not a failure.
+func containerStep(service string, container ProjectContainer, result ActionResult, err error, accepted ResultCode) ProjectStep {
+	step := ProjectStep{
+		Resource: "container",
+		ID:       container.ID,
+		Name:     container.Name,
+		Service:  service,
+		Image:    container.Image,
+		Code:     result.Code,
+		Message:  result.Message,
+	}
+	switch {
+	case err != nil && accepted != "" && isState(err, accepted):
+		step.Code, step.Message = accepted, err.Error()
+	case err != nil:
+		step.Code, step.Message, step.Failed = ResultFailed, err.Error(), true
+	}
+	return step
+}
+
+func removalStep(resource, name, message string, err error) ProjectStep {
+	step := ProjectStep{Resource: resource, ID: name, Name: name, Code: ResultRemoved, Message: message}
+	if err != nil {
+		step.Code, step.Message, step.Failed = ResultFailed, err.Error(), true
+	}
+	return step
+}
@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"Docker_Management/pkg/config"
+
+	"github.com/docker/docker/api/types"
+)
+
+// newShopDaemon holds a Compose project shop: web depends on db, and both
+// run, on the network shop_default with the volume shop_data.
+func newShopDaemon() *fakeDaemon {
+	project := map[string]string{ComposeProjectLabel: "shop"}
+	serviceLabels := func(service, dependsOn string) map[string]string {
+		return map[string]string{ComposeProjectLabel: "shop", ComposeServiceLabel: service, ComposeDependsOnLabel: dependsOn}
+	}
+	return &fakeDaemon{
+		containers: []types.Container{
+			{ID: "web1", Names: []string{"/shop-web-1"}, Image: "nginx", State: "running", Labels: serviceLabels("web", "db:service_started:false")},
+			{ID: "db1", Names: []string{"/shop-db-1"}, Image: "postgres", State: "running", Labels: serviceLabels("db", "")},
+		},
+		networks: []types.NetworkResource{{ID: "net1", Name: "shop_default", Labels: project}},
+		volumes:  []*types.Volume{{Name: "shop_data", Labels: project}},
+	}
+}
+
+func TestStartAndStopProjectFollowDependencies(t *testing.T) {
+	daemon := newShopDaemon()
+	service := NewDockerServiceWithClient(daemon)
+
+	if _, err := service.StopProject(context.Background(), "shop", ProjectActionOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.StartProject(context.Background(), "shop"); err != nil {
+		t.Fatal(err)
+	}
+	want := "[stop web1 stop db1 start db1 start web1]"
+	if got := fmt.Sprint(daemon.calls); got != want {
+		t.Errorf("stop and start made calls %s, want %s", got, want)
+	}
+}
+
+func TestStartProjectSkipsDependentsOfAFailedService(t *testing.T) {
+	daemon := newShopDaemon()
+	for i := range daemon.containers {
+		daemon.containers[i].State = "exited"
+	}
+	daemon.crashing = map[string]bool{"db1": true}
+	service := NewDockerServiceWithClient(daemon)
+
+	result, err := service.StartProject(context.Background(), "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(daemon.calls); got != "[start db1]" {
+		t.Errorf("start made calls %s, want [start db1]", got)
+	}
+	codes := []ResultCode{}
+	for _, step := range result.Steps {
+		codes = append(codes, step.Code)
+	}
+	if want := []ResultCode{ResultExited, ResultSkipped}; fmt.Sprint(codes) != fmt.Sprint(want) {
+		t.Errorf("step codes = %v, want %v", codes, want)
+	}
+}
+
+func TestDownProjectRemovesDependentsFirst(t *testing.T) {
+	daemon := newShopDaemon()
+	service := NewDockerServiceWithClient(daemon)
+
+	result, err := service.DownProject(context.Background(), "shop", ProjectActionOptions{RemoveVolumes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[stop web1 remove container web1 stop db1 remove container db1 remove network shop_default remove volume shop_data]"
+	if got := fmt.Sprint(daemon.calls); got != want || result.Failed != 0 {
+		t.Errorf("down made calls %s with %d failures, want %s", got, result.Failed, want)
+	}
+}
+
+func TestDownProjectLeavesProtectedContainersRunning(t *testing.T) {
+	daemon := newShopDaemon()
+	service := NewDockerServiceWithClient(daemon)
+	protection, err := NewProtectionPolicy([]config.ProtectionRule{{Name: "databases", Types: []string{"containers"}, Names: []string{"*-db-*"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := service.DownProject(context.Background(), "shop", ProjectActionOptions{Protection: protection})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// db1 is neither stopped nor removed; the network it still uses is tried
+	want := "[stop web1 remove container web1 remove network shop_default]"
+	if got := fmt.Sprint(daemon.calls); got != want {
+		t.Errorf("down made calls %s, want %s", got, want)
+	}
+	if db, _ := daemon.container("db1"); daemon.containers[db].State != "running" {
+		t.Errorf("protected container is %s, want running", daemon.containers[db].State)
+	}
+
+	var dbStep *ProjectStep
+	for i, step := range result.Steps {
+		if step.ID == "db1" {
+			dbStep = &result.Steps[i]
+		}
+	}
+	if dbStep == nil || dbStep.Code != ResultSkipped || dbStep.Failed {
+		t.Errorf("db step = %+v, want a skipped step that did not fail", dbStep)
+	}
+}
@@ -2,173 +2,151 @@ package docker
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 )
 
-// ListContainers retrieves the list of Docker containers
-func ListContainers() ([]types.Container, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
+// ListContainers lists the running containers matching filter, or those in
+// the states given by a status filter. With size set the daemon also reports
+// the disk usage of each container, which is slow.
+func (s *DockerService) ListContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error) {
+	// Only list running containers unless other states are asked for
+	filter = filter.Clone()
+	if !filter.Contains("status") {
+		filter.Add("status", "running")
 	}
 
-	// Set up the options for listing only running containers
-	options := types.ContainerListOptions{
-		All:     false, // Only list running containers
-		Filters: filters.NewArgs(),
-	}
-
-	// Add filter for running containers
-	options.Filters.Add("status", "running")
-
-	// List containers
-	containers, err := cli.ContainerList(context.Background(), options)
+	containers, err := s.cli.ContainerList(ctx, types.ContainerListOptions{Filters: filter, Size: size})
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
-	return containers, err
+	return containers, nil
 }
 
-func ListAllContainers() ([]types.Container, error) {
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// ListAllContainers lists the containers matching filter, whatever their state
+func (s *DockerService) ListAllContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error) {
 	options := types.ContainerListOptions{
-		All: true, // List all containers
+		All:     true, // List all containers
+		Filters: filter,
+		Size:    size,
 	}
 
-	containers, err := cli.ContainerList(context.Background(), options)
+	containers, err := s.cli.ContainerList(ctx, options)
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
-	return containers, err
+	return containers, nil
 }
 
 // StartContainer starts a Docker container if it is not already running
-func StartContainer(containerID string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return "", err
-	}
-
+func (s *DockerService) StartContainer(ctx context.Context, containerID string) (ActionResult, error) {
 	// Check the current status of the container
-	containerJSON, err := cli.ContainerInspect(context.Background(), containerID)
+	containerJSON, err := s.inspectForAction(ctx, containerID)
 	if err != nil {
-		return "", err
+		return ActionResult{}, err
 	}
 
 	// Check if the container is already running
 	if containerJSON.State.Running {
-		return "Container is already running", nil
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultAlreadyRunning, Message: "Container is already running"}
 	}
 
 	// Start the container
-	if err := cli.ContainerStart(context.Background(), containerID, types.ContainerStartOptions{}); err != nil {
-		return "", errors.New("failed to start the container: " + err.Error())
+	if err := s.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to start the container: %w", Classify(err))
 	}
 
 	// Check the container's state after starting it
-	containerJSON, err = cli.ContainerInspect(context.Background(), containerID)
+	containerJSON, err = s.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		return "", err
+		return ActionResult{}, err
 	}
 
 	// Check if the container is running
 	if !containerJSON.State.Running {
-		// If the container is not running, check the exit code
-		if containerJSON.State.ExitCode != 0 {
-			return "Failed to start the container, it exited with code " + string(containerJSON.State.ExitCode), nil
-		}
-		return "Failed to start the container, it exited immediately", nil
+		return exitedResult("Failed to start the container", containerJSON.State.ExitCode), nil
 	}
 
-	return "Container started successfully", nil
+	return newActionResult(ResultStarted, "Container started successfully"), nil
 }
 
-func StopContainer(containerID string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return "", err
-	}
-
+// StopContainer stops a running container. The container is killed if it has
+// not exited after timeout; a nil timeout uses the container's own stop timeout.
+func (s *DockerService) StopContainer(ctx context.Context, containerID string, timeout *time.Duration) (ActionResult, error) {
 	// Check the current status of the container
-	containerJSON, err := cli.ContainerInspect(context.Background(), containerID)
+	containerJSON, err := s.inspectForAction(ctx, containerID)
 	if err != nil {
-		return "", err
+		return ActionResult{}, err
 	}
 
 	// Check if the container is not running
 	if !containerJSON.State.Running {
-		return "Specified container is not running", nil
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultNotRunning, Message: "Specified container is not running"}
 	}
 
 	// Stop the container
-	if err := cli.ContainerStop(context.Background(), containerID, nil); err != nil {
-		return "", errors.New("failed to stop the container: " + err.Error())
+	if err := s.cli.ContainerStop(ctx, containerID, timeout); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to stop the container: %w", Classify(err))
 	}
 
-	return "Container stopped successfully", nil
+	return newActionResult(ResultStopped, "Container stopped successfully"), nil
 }
 
-func RemoveContainer(containerID string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// RemoveContainer force-removes a container unless protection refuses it
+func (s *DockerService) RemoveContainer(ctx context.Context, containerID string, protection *ProtectionPolicy) (string, error) {
+	// Check if the container exists
+	containerJSON, err := s.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		return "", err
+		return "", notFound(err, "container", containerID)
 	}
 
-	// Check if the container exists
-	_, err = cli.ContainerInspect(context.Background(), containerID)
-	if err != nil {
-		if client.IsErrNotFound(err) {
-			return "", errors.New("Invalid Container ID")
-		}
+	// Refuse to remove a protected container
+	resource := Resource{Type: PruneContainers, ID: containerJSON.ID, Names: []string{strings.TrimPrefix(containerJSON.Name, "/")}}
+	if containerJSON.Config != nil {
+		resource.Labels = containerJSON.Config.Labels
+	}
+	if err := protection.Check(resource); err != nil {
 		return "", err
 	}
 
 	// Remove the container
-	if err := cli.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
+	if err := s.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
 		return "", err
 	}
 
 	return "Container removed successfully", nil
 }
 
-func RemoveAllContainers() ([]string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// RemoveAllContainers removes every stopped container, skipping protected ones
+func (s *DockerService) RemoveAllContainers(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error) {
 	// Get the list of all containers (including stopped containers)
-	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{All: true})
+	containers, err := s.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Slice to hold the result messages
 	var results []string
+	skipped := []SkippedResource{}
 
 	// Iterate over each container and try to remove it
 	for _, container := range containers {
+		// Leave protected containers alone
+		if protection.skip(Resource{Type: PruneContainers, ID: container.ID, Names: containerNames(container), Labels: container.Labels}, &skipped) {
+			results = append(results, fmt.Sprintf("Skipped protected container: %s", container.ID))
+			continue
+		}
+
 		// Check if the container is running
-		containerJSON, err := cli.ContainerInspect(context.Background(), container.ID)
+		containerJSON, err := s.cli.ContainerInspect(ctx, container.ID)
 		if err != nil {
 			results = append(results, fmt.Sprintf("Failed to inspect container: %s", container.ID))
 			continue
@@ -179,7 +157,7 @@ func RemoveAllContainers() ([]string, error) {
 			results = append(results, fmt.Sprintf("Cannot delete the container, it's in running state: %s", container.ID))
 		} else {
 			// Attempt to remove the container
-			err := cli.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{Force: true})
+			err := s.cli.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{Force: true})
 			if err != nil {
 				results = append(results, fmt.Sprintf("Failed to remove container: %s", container.ID))
 			} else {
@@ -188,23 +166,23 @@ func RemoveAllContainers() ([]string, error) {
 		}
 	}
 
-	return results, nil
+	return results, skipped, nil
 }
 
-func GetContainerLogs(containerID string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return "", err
+// containerNames returns the names of a listed container without the leading slash.
+func containerNames(container types.Container) []string {
+	names := []string{}
+	for _, name := range container.Names {
+		names = append(names, strings.TrimPrefix(name, "/"))
 	}
+	return names
+}
 
+func (s *DockerService) GetContainerLogs(ctx context.Context, containerID string) (string, error) {
 	// Check if the container exists
-	_, err = cli.ContainerInspect(context.Background(), containerID)
+	_, err := s.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		if client.IsErrNotFound(err) {
-			return "", errors.New("Invalid Container ID")
-		}
-		return "", err
+		return "", notFound(err, "container", containerID)
 	}
 
 	// Set up options for retrieving logs
@@ -216,7 +194,7 @@ func GetContainerLogs(containerID string) (string, error) {
 	}
 
 	// Get the logs
-	logReader, err := cli.ContainerLogs(context.Background(), containerID, options)
+	logReader, err := s.cli.ContainerLogs(ctx, containerID, options)
 	if err != nil {
 		return "", err
 	}
@@ -238,79 +216,85 @@ type ContainerStats struct {
 }
 
 // GetContainerStats retrieves statistics for a Docker container by its ID
-func GetContainerStats(containerID string) (ContainerStats, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return ContainerStats{}, err
-	}
-
+func (s *DockerService) GetContainerStats(ctx context.Context, containerID string) (ContainerStats, error) {
 	// Check if the container exists
-	_, err = cli.ContainerInspect(context.Background(), containerID)
+	_, err := s.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		if client.IsErrNotFound(err) {
-			return ContainerStats{}, errors.New("Invalid Container ID")
-		}
-		return ContainerStats{}, err
+		return ContainerStats{}, notFound(err, "container", containerID)
 	}
 
-	// Get the container stats
-	stats, err := cli.ContainerStats(context.Background(), containerID, false)
+	// Take a sample with a valid CPU delta
+	sample, err := s.SampleContainerStats(ctx, containerID)
 	if err != nil {
 		return ContainerStats{}, err
 	}
-	defer stats.Body.Close()
-
-	// Decode the stats
-	var stat types.Stats
-	if err := json.NewDecoder(stats.Body).Decode(&stat); err != nil {
-		return ContainerStats{}, err
-	}
-
-	// Calculate CPU usage percentage
-	cpuUsage := calculateCPUPercentage(stat)
-
-	// Calculate memory usage percentage
-	memoryUsage := calculateMemoryUsagePercentage(stat)
 
 	// Create a ContainerStats instance to return
 	containerStats := ContainerStats{
 		ID:     containerID,
-		CPU:    cpuUsage,
-		Memory: memoryUsage,
+		CPU:    sample.CPUPercent,
+		Memory: sample.MemoryPercent,
 	}
 
 	return containerStats, nil
 }
 
-// Helper function to calculate CPU usage percentage
-func calculateCPUPercentage(stats types.Stats) float64 {
-	// Calculate CPU usage based on the provided stats
-	// Note: This is a basic example; implement the actual calculation based on your requirements
-	return float64(stats.CPUStats.CPUUsage.TotalUsage) / float64(stats.CPUStats.SystemUsage) * 100
+func (s *DockerService) InspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	// Inspect the container
+	containerJSON, err := s.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return types.ContainerJSON{}, notFound(err, "container", containerID)
+	}
+
+	return containerJSON, nil
 }
 
-// Helper function to calculate memory usage percentage
-func calculateMemoryUsagePercentage(stats types.Stats) float64 {
-	// Calculate memory usage based on the provided stats
-	return float64(stats.MemoryStats.Usage) / float64(stats.MemoryStats.Limit) * 100
+// LogStreamOptions controls which log lines StreamContainerLogs returns.
+type LogStreamOptions struct {
+	Since      string // Timestamp or relative duration, e.g. 2024-01-02T15:04:05Z or 10m
+	Until      string // Timestamp or relative duration
+	Tail       string // Number of lines from the end, or "all"
+	Timestamps bool
+	Follow     bool
 }
 
-func InspectContainer(containerID string) (types.ContainerJSON, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// StreamContainerLogs copies a container's stdout and stderr into separate
+// writers until the log ends or ctx is cancelled.
+func (s *DockerService) StreamContainerLogs(ctx context.Context, containerID string, opts LogStreamOptions, stdout, stderr io.Writer) error {
+	// Check if the container exists
+	containerJSON, err := s.cli.ContainerInspect(ctx, containerID)
 	if err != nil {
-		return types.ContainerJSON{}, err
+		return notFound(err, "container", containerID)
 	}
 
-	// Inspect the container
-	containerJSON, err := cli.ContainerInspect(context.Background(), containerID)
+	if opts.Tail == "" {
+		opts.Tail = "all"
+	}
+
+	logReader, err := s.cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      opts.Since,
+		Until:      opts.Until,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+		Follow:     opts.Follow,
+	})
 	if err != nil {
-		if client.IsErrNotFound(err) {
-			return types.ContainerJSON{}, errors.New("Invalid Container ID")
-		}
-		return types.ContainerJSON{}, err
+		return err
+	}
+	defer logReader.Close()
+
+	// TTY containers produce a single raw stream with no stdout/stderr framing
+	if containerJSON.Config != nil && containerJSON.Config.Tty {
+		_, err = io.Copy(stdout, logReader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, logReader)
 	}
 
-	return containerJSON, nil
+	// A cancelled context means the caller went away, which is a normal end of stream
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	return err
 }
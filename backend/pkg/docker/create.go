@@ -0,0 +1,193 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+// ContainerSpec describes a container to create.
+type ContainerSpec struct {
+	Image         string            `json:"image"`
+	Name          string            `json:"name"`
+	Command       []string          `json:"command"`
+	Env           []string          `json:"env"` // KEY=value pairs
+	Ports         []PortSpec        `json:"ports"`
+	Mounts        []MountSpec       `json:"mounts"`
+	Networks      []string          `json:"networks"` // The first network is joined at creation, the rest right after
+	Labels        map[string]string `json:"labels"`
+	RestartPolicy RestartPolicySpec `json:"restart_policy"`
+	Resources     ResourceLimits    `json:"resources"`
+	RegistryAuth  string            `json:"-"` // Encoded credentials for pulling a missing image, see RegistryAuth.Encode
+}
+
+// PortSpec publishes a container port on the host.
+type PortSpec struct {
+	ContainerPort string `json:"container_port"` // e.g. 80 or 53/udp
+	HostIP        string `json:"host_ip"`
+	HostPort      string `json:"host_port"` // Empty picks a random host port
+}
+
+// MountSpec attaches a volume, bind mount or tmpfs to the container.
+type MountSpec struct {
+	Type     string `json:"type"`   // volume, bind or tmpfs
+	Source   string `json:"source"` // Volume name or host path
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// RestartPolicySpec is one of no, always, unless-stopped or on-failure.
+type RestartPolicySpec struct {
+	Name              string `json:"name"`
+	MaximumRetryCount int    `json:"maximum_retry_count"` // Only used by on-failure
+}
+
+// ResourceLimits caps the resources a container may use. Zero means unlimited.
+type ResourceLimits struct {
+	MemoryBytes int64   `json:"memory_bytes"`
+	CPUs        float64 `json:"cpus"`
+	PidsLimit   int64   `json:"pids_limit"`
+}
+
+// CreateResult identifies a newly created container.
+type CreateResult struct {
+	ID       string   `json:"id"`
+	Warnings []string `json:"warnings"`
+}
+
+// CreateContainer creates a container from spec, pulling its image first if it is not available locally.
+func (s *DockerService) CreateContainer(ctx context.Context, spec ContainerSpec) (CreateResult, error) {
+	if spec.Image == "" {
+		return CreateResult{}, invalidInput("image is required")
+	}
+
+	config, hostConfig, err := spec.containerConfig()
+	if err != nil {
+		return CreateResult{}, err
+	}
+
+	// Make sure the image is present
+	if _, err := s.PullImage(ctx, spec.Image, PullOptions{RegistryAuth: spec.RegistryAuth}); err != nil {
+		return CreateResult{}, err
+	}
+
+	var networkingConfig *network.NetworkingConfig
+	if len(spec.Networks) > 0 {
+		hostConfig.NetworkMode = container.NetworkMode(spec.Networks[0])
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{spec.Networks[0]: {}},
+		}
+	}
+
+	created, err := s.cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, spec.Name)
+	if err != nil {
+		return CreateResult{}, err
+	}
+
+	result := CreateResult{ID: created.ID, Warnings: created.Warnings}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+
+	// Older daemons only accept one network at creation, so connect the rest afterwards
+	for i, networkName := range spec.Networks {
+		if i == 0 {
+			continue
+		}
+		if err := s.cli.NetworkConnect(ctx, networkName, created.ID, nil); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to connect to network %s: %v", networkName, err))
+		}
+	}
+
+	return result, nil
+}
+
+// RunContainer creates a container from spec and starts it.
+func (s *DockerService) RunContainer(ctx context.Context, spec ContainerSpec) (CreateResult, ActionResult, error) {
+	result, err := s.CreateContainer(ctx, spec)
+	if err != nil {
+		return CreateResult{}, ActionResult{}, err
+	}
+
+	started, err := s.StartContainer(ctx, result.ID)
+	if err != nil {
+		return result, ActionResult{}, err
+	}
+
+	return result, started, nil
+}
+
+// containerConfig translates spec into the daemon's container and host configuration.
+func (spec ContainerSpec) containerConfig() (*container.Config, *container.HostConfig, error) {
+	config := &container.Config{
+		Image:        spec.Image,
+		Cmd:          spec.Command,
+		Env:          spec.Env,
+		Labels:       spec.Labels,
+		ExposedPorts: nat.PortSet{},
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings: nat.PortMap{},
+		RestartPolicy: container.RestartPolicy{
+			Name:              spec.RestartPolicy.Name,
+			MaximumRetryCount: spec.RestartPolicy.MaximumRetryCount,
+		},
+		Resources: container.Resources{
+			Memory:   spec.Resources.MemoryBytes,
+			NanoCPUs: int64(spec.Resources.CPUs * 1e9),
+		},
+	}
+	if spec.Resources.PidsLimit > 0 {
+		hostConfig.Resources.PidsLimit = &spec.Resources.PidsLimit
+	}
+
+	switch spec.RestartPolicy.Name {
+	case "", "no", "always", "unless-stopped", "on-failure":
+	default:
+		return nil, nil, invalidInput("invalid restart policy: %s", spec.RestartPolicy.Name)
+	}
+
+	for _, portSpec := range spec.Ports {
+		portNumber, proto, _ := strings.Cut(portSpec.ContainerPort, "/")
+		if proto == "" {
+			proto = "tcp"
+		}
+		port, err := nat.NewPort(proto, portNumber)
+		if err != nil {
+			return nil, nil, invalidInput("invalid container port %q: %v", portSpec.ContainerPort, err)
+		}
+		config.ExposedPorts[port] = struct{}{}
+		hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], nat.PortBinding{
+			HostIP:   portSpec.HostIP,
+			HostPort: portSpec.HostPort,
+		})
+	}
+
+	for _, mountSpec := range spec.Mounts {
+		mountType := mount.Type(mountSpec.Type)
+		switch mountType {
+		case mount.TypeVolume, mount.TypeBind, mount.TypeTmpfs:
+		case "":
+			mountType = mount.TypeVolume
+		default:
+			return nil, nil, invalidInput("invalid mount type: %s", mountSpec.Type)
+		}
+		if mountSpec.Target == "" {
+			return nil, nil, invalidInput("mount target is required")
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mountType,
+			Source:   mountSpec.Source,
+			Target:   mountSpec.Target,
+			ReadOnly: mountSpec.ReadOnly,
+		})
+	}
+
+	return config, hostConfig, nil
+}
@@ -0,0 +1,294 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// fakeDaemon is an in-memory daemon holding containers, images, networks and volumes.
+// It records every change made to them in calls, e.g. "stop aaa".
+type fakeDaemon struct {
+	client.APIClient
+
+	mu         sync.Mutex
+	containers []types.Container
+	images     []*types.ImageSummary
+	networks   []types.NetworkResource
+	volumes    []*types.Volume
+	crashing   map[string]bool // Containers that exit as soon as they start
+	calls      []string
+}
+
+func (d *fakeDaemon) record(format string, args ...interface{}) {
+	d.calls = append(d.calls, fmt.Sprintf(format, args...))
+}
+
+// matchesLabelFilter reports whether labels carry every label filter, each key or key=value.
+func matchesLabelFilter(labels map[string]string, filter filters.Args) bool {
+	for _, selector := range filter.Get("label") {
+		key, value, hasValue := strings.Cut(selector, "=")
+		if actual, ok := labels[key]; !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *fakeDaemon) container(id string) (int, bool) {
+	for i, c := range d.containers {
+		if c.ID == id || (len(c.Names) > 0 && c.Names[0] == "/"+id) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *fakeDaemon) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	containers := []types.Container{}
+	for _, c := range d.containers {
+		if (options.All || c.State == "running") && matchesLabelFilter(c.Labels, options.Filters) {
+			containers = append(containers, c)
+		}
+	}
+	return containers, nil
+}
+
+func (d *fakeDaemon) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.container(id)
+	if !ok {
+		return types.ContainerJSON{}, errdefs.NotFound(fmt.Errorf("no such container: %s", id))
+	}
+	c := d.containers[i]
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: c.ID, Name: c.Names[0], State: &types.ContainerState{Status: c.State, Running: c.State == "running"}},
+		Config:            &container.Config{Image: c.Image, Labels: c.Labels},
+	}, nil
+}
+
+func (d *fakeDaemon) ContainerStart(ctx context.Context, id string, options types.ContainerStartOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.container(id)
+	if !ok {
+		return errdefs.NotFound(fmt.Errorf("no such container: %s", id))
+	}
+	d.containers[i].State = "running"
+	if d.crashing[d.containers[i].ID] {
+		d.containers[i].State = "exited"
+	}
+	d.record("start %s", d.containers[i].ID)
+	return nil
+}
+
+func (d *fakeDaemon) ContainerStop(ctx context.Context, id string, timeout *time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.container(id)
+	if !ok {
+		return errdefs.NotFound(fmt.Errorf("no such container: %s", id))
+	}
+	d.containers[i].State = "exited"
+	d.record("stop %s", d.containers[i].ID)
+	return nil
+}
+
+func (d *fakeDaemon) ContainerRemove(ctx context.Context, id string, options types.ContainerRemoveOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.container(id)
+	if !ok {
+		return errdefs.NotFound(fmt.Errorf("no such container: %s", id))
+	}
+	d.record("remove container %s", d.containers[i].ID)
+	d.containers = append(d.containers[:i], d.containers[i+1:]...)
+	return nil
+}
+
+func (d *fakeDaemon) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	networks := []types.NetworkResource{}
+	for _, n := range d.networks {
+		if matchesLabelFilter(n.Labels, options.Filters) {
+			networks = append(networks, n)
+		}
+	}
+	return networks, nil
+}
+
+func (d *fakeDaemon) NetworkInspect(ctx context.Context, id string, options types.NetworkInspectOptions) (types.NetworkResource, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, n := range d.networks {
+		if n.ID == id || n.Name == id {
+			return n, nil
+		}
+	}
+	return types.NetworkResource{}, errdefs.NotFound(fmt.Errorf("no such network: %s", id))
+}
+
+func (d *fakeDaemon) NetworkRemove(ctx context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, n := range d.networks {
+		if n.ID == id || n.Name == id {
+			d.record("remove network %s", n.Name)
+			d.networks = append(d.networks[:i], d.networks[i+1:]...)
+			return nil
+		}
+	}
+	return errdefs.NotFound(fmt.Errorf("no such network: %s", id))
+}
+
+func (d *fakeDaemon) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	volumes := []*types.Volume{}
+	for _, v := range d.volumes {
+		if matchesLabelFilter(v.Labels, filter) {
+			volumes = append(volumes, v)
+		}
+	}
+	return volumetypes.VolumeListOKBody{Volumes: volumes}, nil
+}
+
+func (d *fakeDaemon) VolumeInspect(ctx context.Context, name string) (types.Volume, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, v := range d.volumes {
+		if v.Name == name {
+			return *v, nil
+		}
+	}
+	return types.Volume{}, errdefs.NotFound(fmt.Errorf("no such volume: %s", name))
+}
+
+func (d *fakeDaemon) VolumeRemove(ctx context.Context, name string, force bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, v := range d.volumes {
+		if v.Name == name {
+			d.record("remove volume %s", name)
+			d.volumes = append(d.volumes[:i], d.volumes[i+1:]...)
+			return nil
+		}
+	}
+	return errdefs.NotFound(fmt.Errorf("no such volume: %s", name))
+}
+
+func (d *fakeDaemon) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return types.DiskUsage{Images: append([]*types.ImageSummary(nil), d.images...)}, nil
+}
+
+// image finds an image by ID or tag.
+func (d *fakeDaemon) image(reference string) (int, bool) {
+	for i, image := range d.images {
+		if image.ID == reference {
+			return i, true
+		}
+		for _, tag := range image.RepoTags {
+			if tag == reference {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (d *fakeDaemon) ImageInspectWithRaw(ctx context.Context, reference string) (types.ImageInspect, []byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.image(reference)
+	if !ok {
+		return types.ImageInspect{}, nil, errdefs.NotFound(fmt.Errorf("no such image: %s", reference))
+	}
+	return types.ImageInspect{ID: d.images[i].ID, RepoTags: d.images[i].RepoTags}, nil, nil
+}
+
+func (d *fakeDaemon) ImagePull(ctx context.Context, reference string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.record("pull %s", reference)
+	if _, ok := d.image(reference); !ok {
+		d.images = append(d.images, &types.ImageSummary{ID: "sha256:" + reference, RepoTags: []string{reference}})
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (d *fakeDaemon) ImageTag(ctx context.Context, source, target string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.image(source)
+	if !ok {
+		return errdefs.NotFound(fmt.Errorf("no such image: %s", source))
+	}
+	d.record("tag %s %s", source, target)
+	d.images[i].RepoTags = append(d.images[i].RepoTags, target)
+	return nil
+}
+
+func (d *fakeDaemon) ImagePush(ctx context.Context, reference string, options types.ImagePushOptions) (io.ReadCloser, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.record("push %s", reference)
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+// ImageRemove untags a tag the image has others besides, and otherwise removes the image.
+func (d *fakeDaemon) ImageRemove(ctx context.Context, reference string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.image(reference)
+	if !ok {
+		return nil, errdefs.NotFound(fmt.Errorf("no such image: %s", reference))
+	}
+	image := d.images[i]
+	if reference != image.ID && len(image.RepoTags) > 1 {
+		d.record("untag %s", reference)
+		tags := []string{}
+		for _, tag := range image.RepoTags {
+			if tag != reference {
+				tags = append(tags, tag)
+			}
+		}
+		image.RepoTags = tags
+		return []types.ImageDeleteResponseItem{{Untagged: reference}}, nil
+	}
+
+	d.record("remove image %s", reference)
+	d.images = append(d.images[:i], d.images[i+1:]...)
+	return []types.ImageDeleteResponseItem{{Deleted: image.ID}}, nil
+}
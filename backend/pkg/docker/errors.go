@@ -0,0 +1,133 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// NotFoundError reports a container, image, volume, network, exec instance,
+// job or registry credential that does not exist.
+type NotFoundError struct {
+	Kind string // e.g. container or image
+	ID   string // The ID or name as given
+	Err  error  // The daemon's error, if the kind is not known
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Kind == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("no such %s: %s", e.Kind, e.ID)
+}
+
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// ConflictError reports a resource that is in use or that clashes with an
+// existing one, e.g. an image used by a container or a taken name.
+type ConflictError struct {
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string { return e.Message }
+
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// StateError reports a container that is not in the state an action needs.
+// Code is ResultAlreadyRunning, ResultNotRunning, ResultAlreadyPaused or
+// ResultNotPaused.
+type StateError struct {
+	ContainerID string
+	Code        ResultCode
+	Message     string
+}
+
+func (e *StateError) Error() string { return e.Message }
+
+// InvalidInputError reports a request that cannot succeed as given.
+type InvalidInputError struct {
+	Message string
+	Err     error
+}
+
+func (e *InvalidInputError) Error() string { return e.Message }
+
+func (e *InvalidInputError) Unwrap() error { return e.Err }
+
+// UnavailableError reports a Docker daemon that cannot be reached.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return "Docker daemon unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
+func invalidInput(format string, args ...interface{}) error {
+	return &InvalidInputError{Message: fmt.Sprintf(format, args...)}
+}
+
+// conflictPhrases identify conflicts in daemon error messages. This client
+// version only types 404 responses, so a 409 reaches us as plain text.
+var conflictPhrases = []string{"conflict", "in use", "is using", "is being used", "has active endpoints", "already exists"}
+
+// invalidPhrases identify rejected parameters in daemon error messages.
+var invalidPhrases = []string{"invalid reference format", "invalid parameter", "invalid argument", "bad parameter", "invalid filter"}
+
+// Classify returns a daemon error as one of the typed errors above when it
+// can tell what went wrong, and err unchanged otherwise.
+func Classify(err error) error {
+	if err == nil || isTyped(err) {
+		return err
+	}
+
+	switch {
+	case client.IsErrNotFound(err):
+		return &NotFoundError{Err: err}
+	case client.IsErrConnectionFailed(err) || errdefs.IsUnavailable(err):
+		return &UnavailableError{Err: err}
+	case errdefs.IsConflict(err):
+		return &ConflictError{Message: err.Error(), Err: err}
+	case errdefs.IsInvalidParameter(err):
+		return &InvalidInputError{Message: err.Error(), Err: err}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, phrase := range conflictPhrases {
+		if strings.Contains(message, phrase) {
+			return &ConflictError{Message: err.Error(), Err: err}
+		}
+	}
+	for _, phrase := range invalidPhrases {
+		if strings.Contains(message, phrase) {
+			return &InvalidInputError{Message: err.Error(), Err: err}
+		}
+	}
+	return err
+}
+
+// isTyped reports whether err already is, or wraps, one of the typed errors.
+func isTyped(err error) bool {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	var state *StateError
+	var invalid *InvalidInputError
+	var unavailable *UnavailableError
+	var protected *ProtectedError
+	return errors.As(err, &notFound) || errors.As(err, &conflict) || errors.As(err, &state) ||
+		errors.As(err, &invalid) || errors.As(err, &unavailable) || errors.As(err, &protected)
+}
+
+// notFound classifies an error from looking up the kind of resource named id.
+func notFound(err error, kind, id string) error {
+	if client.IsErrNotFound(err) {
+		return &NotFoundError{Kind: kind, ID: id}
+	}
+	return Classify(err)
+}
@@ -0,0 +1,211 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+const (
+	eventBufferSize    = 64
+	eventRetryMinDelay = time.Second
+	eventRetryMaxDelay = 30 * time.Second
+	eventDropLogEvery  = 100 // Log every Nth event dropped for a slow subscriber
+)
+
+// hubEventTypes are the daemon event types the hub subscribes to.
+var hubEventTypes = []string{
+	events.ContainerEventType,
+	events.ImageEventType,
+	events.VolumeEventType,
+	events.NetworkEventType,
+}
+
+// Event is a daemon event labelled with the host it was observed on.
+type Event struct {
+	Host string `json:"host"`
+	events.Message
+}
+
+// EventFilter selects events for a subscriber. Empty fields match everything;
+// values within a field are alternatives and fields must all match.
+type EventFilter struct {
+	Hosts   []string
+	Types   []string
+	Actions []string
+	Labels  []string // "key" or "key=value", matched against the actor attributes
+}
+
+// Matches reports whether event passes the filter.
+func (f EventFilter) Matches(event Event) bool {
+	if !matchesAny(f.Hosts, event.Host) || !matchesAny(f.Types, event.Type) || !matchesAny(f.Actions, eventAction(event.Action)) {
+		return false
+	}
+	for _, label := range f.Labels {
+		key, value, hasValue := strings.Cut(label, "=")
+		actual, ok := event.Actor.Attributes[key]
+		if !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// eventAction strips the command suffix from actions such as "exec_start: sh".
+func eventAction(action string) string {
+	if i := strings.Index(action, ":"); i >= 0 {
+		return action[:i]
+	}
+	return action
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// EventSubscription receives the events that match its filter. Events are
+// dropped rather than queued when the subscriber falls behind.
+type EventSubscription struct {
+	filter  EventFilter
+	events  chan Event
+	dropped int
+}
+
+// Events returns the channel events are delivered on. It is closed by Unsubscribe.
+func (s *EventSubscription) Events() <-chan Event {
+	return s.events
+}
+
+// EventHub follows the event stream of every registered host and fans the
+// events out to subscribers, so the daemons are watched once however many
+// clients are listening.
+type EventHub struct {
+	hosts *HostRegistry
+
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+}
+
+// NewEventHub creates an EventHub for the hosts in registry. Call Run to start it.
+func NewEventHub(hosts *HostRegistry) *EventHub {
+	return &EventHub{
+		hosts:       hosts,
+		subscribers: map[*EventSubscription]struct{}{},
+	}
+}
+
+// Subscribe registers a subscriber for the events matching filter.
+func (h *EventHub) Subscribe(filter EventFilter) *EventSubscription {
+	subscription := &EventSubscription{
+		filter: filter,
+		events: make(chan Event, eventBufferSize),
+	}
+
+	h.mu.Lock()
+	h.subscribers[subscription] = struct{}{}
+	h.mu.Unlock()
+	return subscription
+}
+
+// Unsubscribe removes subscription and closes its channel.
+func (h *EventHub) Unsubscribe(subscription *EventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[subscription]; ok {
+		delete(h.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// publish delivers event to every matching subscriber without blocking.
+func (h *EventHub) publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			if subscription.dropped%eventDropLogEvery == 0 {
+				log.Printf("Dropping events for a slow subscriber (%d dropped so far)", subscription.dropped+1)
+			}
+			subscription.dropped++
+		}
+	}
+}
+
+// Run follows every registered host until ctx is cancelled.
+func (h *EventHub) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, name := range h.hosts.Names() {
+		service, err := h.hosts.Get(name)
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, service Service) {
+			defer wg.Done()
+			h.follow(ctx, name, service)
+		}(name, service)
+	}
+	wg.Wait()
+}
+
+// follow reads one host's event stream, reconnecting with exponential backoff
+// whenever the stream fails.
+func (h *EventHub) follow(ctx context.Context, hostName string, service Service) {
+	eventFilter := filters.NewArgs()
+	for _, eventType := range hubEventTypes {
+		eventFilter.Add("type", eventType)
+	}
+
+	delay := eventRetryMinDelay
+	for {
+		messages, errs := service.Events(ctx, types.EventsOptions{Filters: eventFilter})
+
+	receive:
+		for {
+			select {
+			case message := <-messages:
+				// A working stream resets the backoff
+				delay = eventRetryMinDelay
+				h.publish(Event{Host: hostName, Message: message})
+			case err := <-errs:
+				if ctx.Err() == nil {
+					log.Printf("Event stream on host %s ended, reconnecting in %s: %v", hostName, delay, err)
+				}
+				break receive
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > eventRetryMaxDelay {
+			delay = eventRetryMaxDelay
+		}
+	}
+}
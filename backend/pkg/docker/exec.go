@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// execExitPollInterval and execExitPollAttempts bound how long RunExec waits
+// for the daemon to report an exit code once the output has ended.
+const (
+	execExitPollInterval = 100 * time.Millisecond
+	execExitPollAttempts = 20
+)
+
+// ExecExitUnknown is returned by RunExec when the command was still running
+// after its output ended and the polls ran out, so it has no exit code yet.
+const ExecExitUnknown = -1
+
+// ExecSpec describes a command to run inside a running container.
+type ExecSpec struct {
+	Cmd        []string `json:"cmd"`
+	User       string   `json:"user"`
+	Env        []string `json:"env"` // KEY=value pairs
+	WorkingDir string   `json:"workdir"`
+	Tty        bool     `json:"tty"`
+}
+
+// CreateExec prepares spec to run in a running container and returns the exec ID.
+func (s *DockerService) CreateExec(ctx context.Context, containerID string, spec ExecSpec) (string, error) {
+	if len(spec.Cmd) == 0 {
+		return "", invalidInput("command is required")
+	}
+
+	// Check the current status of the container
+	containerJSON, err := s.inspectForAction(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	if !containerJSON.State.Running {
+		return "", &StateError{ContainerID: containerID, Code: ResultNotRunning, Message: "Specified container is not running"}
+	}
+
+	exec, err := s.cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		User:         spec.User,
+		Tty:          spec.Tty,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          spec.Env,
+		WorkingDir:   spec.WorkingDir,
+		Cmd:          spec.Cmd,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return exec.ID, nil
+}
+
+// RunExec starts an exec instance and connects it to the given streams until
+// the command finishes or ctx is cancelled, then returns its exit code, or
+// ExecExitUnknown if the daemon never reports one. tty must
+// match the exec's configuration, since a TTY exec has a single raw output stream.
+func (s *DockerService) RunExec(ctx context.Context, execID string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	resp, err := s.cli.ContainerExecAttach(ctx, execID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Close()
+
+	// The hijacked connection ignores ctx, so close it when ctx ends
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		io.Copy(resp.Conn, stdin)
+		resp.CloseWrite()
+	}()
+
+	if tty {
+		_, err = io.Copy(stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+	}
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The output can end slightly before the daemon records the exit code
+	for attempt := 0; ; attempt++ {
+		inspect, err := s.cli.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		if attempt == execExitPollAttempts {
+			return ExecExitUnknown, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(execExitPollInterval):
+		}
+	}
+}
+
+// ResizeExec changes the terminal size of a TTY exec instance.
+func (s *DockerService) ResizeExec(ctx context.Context, execID string, rows, cols uint) error {
+	return s.cli.ContainerExecResize(ctx, execID, types.ResizeOptions{Height: rows, Width: cols})
+}
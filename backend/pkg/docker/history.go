@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"Docker_Management/pkg/db"
+	"Docker_Management/pkg/models"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+// historyEventActions are the daemon event actions worth keeping in the lifecycle history.
+var historyEventActions = []string{
+	"create", "start", "restart", "stop", "die", "kill", "oom", "pause", "unpause", "rename", "destroy", // Containers
+	"pull", "tag", "untag", "delete", "import", "load", // Images
+}
+
+// RecordDaemonEvents stores the container and image lifecycle events
+// published by hub until ctx is cancelled.
+func RecordDaemonEvents(ctx context.Context, hub *EventHub, store db.HistoryStore) {
+	subscription := hub.Subscribe(EventFilter{
+		Types:   []string{events.ContainerEventType, events.ImageEventType},
+		Actions: historyEventActions,
+	})
+	defer hub.Unsubscribe(subscription)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-subscription.Events():
+			if err := store.Insert(ctx, historyRecordFromEvent(event.Host, event.Message)); err != nil {
+				log.Printf("Failed to record %s event on host %s: %v", event.Action, event.Host, err)
+			}
+		}
+	}
+}
+
+// historyRecordFromEvent converts a daemon event into a history record.
+func historyRecordFromEvent(hostName string, message events.Message) models.HistoryRecord {
+	record := models.HistoryRecord{
+		Host:      hostName,
+		Action:    message.Action,
+		Actor:     models.HistorySourceDaemon,
+		Source:    models.HistorySourceDaemon,
+		Timestamp: time.Unix(0, message.TimeNano).UTC(),
+		Outcome:   models.OutcomeSuccess,
+	}
+
+	attributes := message.Actor.Attributes
+	switch message.Type {
+	case events.ContainerEventType:
+		record.ContainerID = message.Actor.ID
+		record.Image = attributes["image"]
+		if name := attributes["name"]; name != "" {
+			record.ContainerNames = []string{name}
+		}
+		if exitCode := attributes["exitCode"]; exitCode != "" {
+			record.Message = "exit code " + exitCode
+			if exitCode != "0" {
+				record.Outcome = models.OutcomeFailure
+			}
+		}
+		if oldName := attributes["oldName"]; oldName != "" {
+			record.Message = "renamed from " + strings.TrimPrefix(oldName, "/")
+		}
+	case events.ImageEventType:
+		record.Image = message.Actor.ID
+		if name := attributes["name"]; name != "" {
+			record.Image = name
+		}
+	}
+
+	return record
+}
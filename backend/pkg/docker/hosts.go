@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"fmt"
+
+	"Docker_Management/pkg/config"
+)
+
+// AllHosts is the host selector that fans a list request out to every registered host.
+const AllHosts = "all"
+
+// HostInfo describes a registered Docker host.
+type HostInfo struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Default  bool   `json:"default"`
+}
+
+// HostRegistry holds one Service per managed Docker host.
+type HostRegistry struct {
+	names    []string // Registration order; names[0] is the default host
+	services map[string]Service
+}
+
+// NewHostRegistry connects to every configured host. The first host becomes the default.
+func NewHostRegistry(hosts []config.DockerConfig) (*HostRegistry, error) {
+	registry := &HostRegistry{services: map[string]Service{}}
+
+	for _, host := range hosts {
+		service, err := NewDockerService(host)
+		if err != nil {
+			registry.Close()
+			return nil, fmt.Errorf("failed to create client for host %s: %v", host.Name, err)
+		}
+		if err := registry.Add(host.Name, service); err != nil {
+			service.Close()
+			registry.Close()
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Add registers a Service under name, e.g. a stub in tests.
+func (r *HostRegistry) Add(name string, service Service) error {
+	if name == "" || name == AllHosts {
+		return fmt.Errorf("invalid host name: %q", name)
+	}
+	if _, exists := r.services[name]; exists {
+		return fmt.Errorf("duplicate host name: %s", name)
+	}
+
+	r.names = append(r.names, name)
+	r.services[name] = service
+	return nil
+}
+
+// Get returns the Service for the named host, or the default host when name is empty.
+func (r *HostRegistry) Get(name string) (Service, error) {
+	if name == "" {
+		name = r.DefaultHost()
+	}
+
+	service, ok := r.services[name]
+	if !ok {
+		return nil, invalidInput("unknown Docker host: %s", name)
+	}
+	return service, nil
+}
+
+// DefaultHost returns the name of the default host, or "" if none are registered.
+func (r *HostRegistry) DefaultHost() string {
+	if len(r.names) == 0 {
+		return ""
+	}
+	return r.names[0]
+}
+
+// Names returns the registered host names in registration order.
+func (r *HostRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Hosts describes every registered host.
+func (r *HostRegistry) Hosts() []HostInfo {
+	hosts := []HostInfo{}
+	for i, name := range r.names {
+		hosts = append(hosts, HostInfo{
+			Name:     name,
+			Endpoint: r.services[name].DaemonHost(),
+			Default:  i == 0,
+		})
+	}
+	return hosts
+}
+
+// Close releases the clients of every registered host.
+func (r *HostRegistry) Close() error {
+	var firstErr error
+	for _, name := range r.names {
+		if err := r.services[name].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
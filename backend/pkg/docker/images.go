@@ -2,41 +2,33 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 )
 
-func ListImages() ([]types.ImageSummary, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// ListImages lists the images matching filter
+func (s *DockerService) ListImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error) {
 	// List images
-	images, err := cli.ImageList(context.Background(), types.ImageListOptions{})
+	images, err := s.cli.ImageList(ctx, types.ImageListOptions{Filters: filter})
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	return images, nil
 }
 
-func ListDanglingImages() ([]types.ImageSummary, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// ListDanglingImages lists the untagged images matching filter
+func (s *DockerService) ListDanglingImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error) {
 	// Get all images
-	images, err := cli.ImageList(context.Background(), types.ImageListOptions{})
+	images, err := s.cli.ImageList(ctx, types.ImageListOptions{Filters: filter})
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	// Filter for dangling images
@@ -50,15 +42,23 @@ func ListDanglingImages() ([]types.ImageSummary, error) {
 	return danglingImages, nil
 }
 
-func RemoveImage(imageID string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// RemoveImage force-removes an image unless protection refuses it
+func (s *DockerService) RemoveImage(ctx context.Context, imageID string, protection *ProtectionPolicy) (string, error) {
+	// Refuse to remove a protected image
+	image, _, err := s.cli.ImageInspectWithRaw(ctx, imageID)
 	if err != nil {
+		return "", notFound(err, "image", imageID)
+	}
+	resource := Resource{Type: PruneImages, ID: image.ID, Names: image.RepoTags}
+	if image.Config != nil {
+		resource.Labels = image.Config.Labels
+	}
+	if err := protection.Check(resource); err != nil {
 		return "", err
 	}
 
 	// Remove the image
-	_, err = cli.ImageRemove(context.Background(), imageID, types.ImageRemoveOptions{Force: true})
+	_, err = s.cli.ImageRemove(ctx, imageID, types.ImageRemoveOptions{Force: true})
 	if err != nil {
 		return "", err
 	}
@@ -66,25 +66,27 @@ func RemoveImage(imageID string) (string, error) {
 	return "Image removed successfully", nil
 }
 
-func RemoveAllImages() ([]string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// RemoveAllImages force-removes every image, skipping protected ones
+func (s *DockerService) RemoveAllImages(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error) {
 	// Get the list of all images
-	images, err := cli.ImageList(context.Background(), types.ImageListOptions{All: true})
+	images, err := s.cli.ImageList(ctx, types.ImageListOptions{All: true})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Slice to hold the result messages
 	var results []string
+	skipped := []SkippedResource{}
 
 	// Iterate over each image and try to remove it
 	for _, image := range images {
-		_, err := cli.ImageRemove(context.Background(), image.ID, types.ImageRemoveOptions{Force: true})
+		// Leave protected images alone
+		if protection.skip(Resource{Type: PruneImages, ID: image.ID, Names: taggedReferences(image.RepoTags), Labels: image.Labels}, &skipped) {
+			results = append(results, fmt.Sprintf("Skipped protected image: %s", image.ID))
+			continue
+		}
+
+		_, err := s.cli.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{Force: true})
 		if err != nil {
 			// Append error message if the image is being used
 			results = append(results, fmt.Sprintf("Cannot remove, image is being used: %s", image.ID))
@@ -94,38 +96,40 @@ func RemoveAllImages() ([]string, error) {
 		}
 	}
 
-	return results, nil
+	return results, skipped, nil
 }
 
-func RemoveAllDanglingImages() ([]string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+// RemoveAllDanglingImages force-removes every dangling image, skipping protected ones
+func (s *DockerService) RemoveAllDanglingImages(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error) {
 	// Set up filter to list only dangling images
 	imageFilter := filters.NewArgs()
 	imageFilter.Add("dangling", "true")
 
 	// Get the list of dangling images
-	images, err := cli.ImageList(context.Background(), types.ImageListOptions{Filters: imageFilter})
+	images, err := s.cli.ImageList(ctx, types.ImageListOptions{Filters: imageFilter})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if there are no dangling images
 	if len(images) == 0 {
-		return []string{"No dangling images found"}, nil
+		return []string{"No dangling images found"}, []SkippedResource{}, nil
 	}
 
 	// Slice to hold the result messages
 	var results []string
+	skipped := []SkippedResource{}
 
 	// Iterate over each image and try to remove it
 	for _, image := range images {
+		// Leave protected images alone
+		if protection.skip(Resource{Type: PruneImages, ID: image.ID, Labels: image.Labels}, &skipped) {
+			results = append(results, fmt.Sprintf("Skipped protected image: %s", image.ID))
+			continue
+		}
+
 		// Attempt to remove the image
-		_, err := cli.ImageRemove(context.Background(), image.ID, types.ImageRemoveOptions{Force: true})
+		_, err := s.cli.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{Force: true})
 		if err != nil {
 			results = append(results, fmt.Sprintf("Failed to remove image: %s", image.ID))
 		} else {
@@ -133,17 +137,11 @@ func RemoveAllDanglingImages() ([]string, error) {
 		}
 	}
 
-	return results, err
+	return results, skipped, err
 }
-func InspectImage(imageID string) (types.ImageInspect, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return types.ImageInspect{}, err
-	}
-
+func (s *DockerService) InspectImage(ctx context.Context, imageID string) (types.ImageInspect, error) {
 	// Inspect the image
-	imageInspect, _, err := cli.ImageInspectWithRaw(context.Background(), imageID)
+	imageInspect, _, err := s.cli.ImageInspectWithRaw(ctx, imageID)
 	if err != nil {
 		return types.ImageInspect{}, err
 	}
@@ -151,42 +149,60 @@ func InspectImage(imageID string) (types.ImageInspect, error) {
 	return imageInspect, err
 }
 
-func PullImage(image string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return "", err
-	}
+// PullOptions controls how PullImage fetches an image.
+type PullOptions struct {
+	RegistryAuth string                        // Encoded credentials, see RegistryAuth.Encode
+	Progress     func(jsonmessage.JSONMessage) // Receives each progress message, if set
+	Force        bool                          // Pull even if the image already exists locally
+}
+
+// PullImage pulls an image from Docker hub or a registry unless it already exists locally.
+func (s *DockerService) PullImage(ctx context.Context, image string, opts PullOptions) (string, error) {
 	// Check if the image already exists locally
-	_, _, err = cli.ImageInspectWithRaw(context.Background(), image)
-	if err == nil {
-		return fmt.Sprintf("Specified image '%s' already exists", image), nil
+	_, _, err := s.cli.ImageInspectWithRaw(ctx, image)
+	if err == nil && !opts.Force {
+		message := fmt.Sprintf("Specified image '%s' already exists", image)
+		if opts.Progress != nil {
+			opts.Progress(jsonmessage.JSONMessage{Status: message})
+		}
+		return message, nil
 	}
-	// Create an options object for pulling the image
-	options := types.ImagePullOptions{}
 
 	// Pull the image from Docker hub or a registry
-	reader, err := cli.ImagePull(context.Background(), image, options)
+	reader, err := s.cli.ImagePull(ctx, image, types.ImagePullOptions{RegistryAuth: opts.RegistryAuth})
 	if err != nil {
-		return "", fmt.Errorf("failed to pull image: %v", err)
+		return "", fmt.Errorf("failed to pull image: %w", Classify(err))
 	}
 	defer reader.Close()
 
-	// Read the output from pulling the image
-	output := ""
-	buf := make([]byte, 8)
+	if err := decodeProgress(reader, opts.Progress); err != nil {
+		return "", fmt.Errorf("failed to pull image: %w", Classify(err))
+	}
+
+	return fmt.Sprintf("Image %s pulled successfully", image), nil
+}
+
+// decodeProgress reads the JSON progress messages of a pull, push or build,
+// passing each to progress if set. An error message in the stream fails the operation.
+func decodeProgress(reader io.Reader, progress func(jsonmessage.JSONMessage)) error {
+	decoder := json.NewDecoder(reader)
 	for {
-		n, err := reader.Read(buf)
-		if n > 0 {
-			output += string(buf[:n])
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
 		}
-		if err == io.EOF {
-			break
+
+		if progress != nil {
+			progress(message)
 		}
-		if err != nil {
-			return "", err
+		if message.Error != nil {
+			return errors.New(message.Error.Message)
+		}
+		if message.ErrorMessage != "" {
+			return errors.New(message.ErrorMessage)
 		}
 	}
-
-	return fmt.Sprintf("Image %s pulled successfully", image), nil
 }
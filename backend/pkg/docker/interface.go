@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// Service is the set of operations on one Docker host that the API and the
+// event hub use. DockerService implements it against a daemon; tests can
+// register a stub in its place.
+type Service interface {
+	DaemonHost() string
+	Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error)
+	LookupResource(ctx context.Context, resourceType PruneType, id string) (Resource, error)
+	Prune(ctx context.Context, opts PruneOptions, protection *ProtectionPolicy) (PruneReport, error)
+	Close() error
+
+	// Containers
+	ListContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error)
+	ListAllContainers(ctx context.Context, filter filters.Args, size bool) ([]types.Container, error)
+	CreateContainer(ctx context.Context, spec ContainerSpec) (CreateResult, error)
+	RunContainer(ctx context.Context, spec ContainerSpec) (CreateResult, ActionResult, error)
+	InspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	StartContainer(ctx context.Context, containerID string) (ActionResult, error)
+	StopContainer(ctx context.Context, containerID string, timeout *time.Duration) (ActionResult, error)
+	RestartContainer(ctx context.Context, containerID string, timeout *time.Duration) (ActionResult, error)
+	PauseContainer(ctx context.Context, containerID string) (ActionResult, error)
+	UnpauseContainer(ctx context.Context, containerID string) (ActionResult, error)
+	KillContainer(ctx context.Context, containerID string, signal string) (ActionResult, error)
+	RenameContainer(ctx context.Context, containerID string, newName string) (ActionResult, error)
+	WaitContainer(ctx context.Context, containerID string, condition string) (ActionResult, error)
+	RemoveContainer(ctx context.Context, containerID string, protection *ProtectionPolicy) (string, error)
+	RemoveAllContainers(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error)
+	GetContainerLogs(ctx context.Context, containerID string) (string, error)
+	StreamContainerLogs(ctx context.Context, containerID string, opts LogStreamOptions, stdout, stderr io.Writer) error
+	GetContainerStats(ctx context.Context, containerID string) (ContainerStats, error)
+	StreamContainerStats(ctx context.Context, containerIDs []string, interval time.Duration, emit func([]StatsSample) error) error
+	ExportContainer(ctx context.Context, containerID string, w io.Writer) error
+
+	// Exec sessions
+	CreateExec(ctx context.Context, containerID string, spec ExecSpec) (string, error)
+	RunExec(ctx context.Context, execID string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	ResizeExec(ctx context.Context, execID string, rows, cols uint) error
+	ExecContainer(ctx context.Context, execID string) (string, error)
+
+	// Images
+	ListImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error)
+	ListDanglingImages(ctx context.Context, filter filters.Args) ([]types.ImageSummary, error)
+	InspectImage(ctx context.Context, imageID string) (types.ImageInspect, error)
+	ImageHistory(ctx context.Context, imageID string) ([]ImageLayer, error)
+	ImageUsage(ctx context.Context) (ImageUsageReport, error)
+	CheckImagesExist(ctx context.Context, images []string) error
+	PullImage(ctx context.Context, image string, opts PullOptions) (string, error)
+	PushImage(ctx context.Context, reference string, opts PushOptions) (string, error)
+	MirrorImage(ctx context.Context, source, target string, opts MirrorOptions) (string, error)
+	BuildImage(ctx context.Context, buildContext io.Reader, spec BuildSpec, progress func(jsonmessage.JSONMessage)) (string, error)
+	TagImage(ctx context.Context, source, target string) (string, error)
+	UntagImage(ctx context.Context, reference string) (string, error)
+	SaveImages(ctx context.Context, images []string, w io.Writer) error
+	LoadImages(ctx context.Context, tarball io.Reader) ([]string, error)
+	ImportImage(ctx context.Context, tarball io.Reader, opts ImportOptions) (string, error)
+	RemoveImage(ctx context.Context, imageID string, protection *ProtectionPolicy) (string, error)
+	RemoveAllImages(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error)
+	RemoveAllDanglingImages(ctx context.Context, protection *ProtectionPolicy) ([]string, []SkippedResource, error)
+
+	// Volumes
+	ListVolumes(ctx context.Context, filter filters.Args) ([]*types.Volume, error)
+	InspectVolume(ctx context.Context, volumeName string) (*types.Volume, error)
+	ListContainersAttachedToVolume(ctx context.Context, volumeName string) ([]string, error)
+	RemoveVolume(ctx context.Context, volumeName string, protection *ProtectionPolicy) (string, error)
+
+	// Networks
+	ListNetworks(ctx context.Context, filter filters.Args) ([]types.NetworkResource, error)
+	InspectNetwork(ctx context.Context, networkID string) (map[string]interface{}, error)
+	ListContainersInNetwork(ctx context.Context, networkID string) ([]NetworkContainer, error)
+	RemoveNetwork(ctx context.Context, networkID string, protection *ProtectionPolicy) (string, error)
+
+	// Compose projects
+	ListProjects(ctx context.Context, inScope func(labels map[string]string) bool) ([]Project, error)
+	InspectProject(ctx context.Context, name string, inScope func(labels map[string]string) bool) (Project, error)
+	StartProject(ctx context.Context, name string) (ProjectActionResult, error)
+	StopProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error)
+	RestartProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error)
+	DownProject(ctx context.Context, name string, opts ProjectActionOptions) (ProjectActionResult, error)
+}
+
+var _ Service = (*DockerService)(nil)
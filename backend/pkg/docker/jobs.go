@@ -0,0 +1,283 @@
+package docker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// jobRetention is how long finished jobs stay available for polling.
+const jobRetention = time.Hour
+
+// JobStatus is the state of a background job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// LayerProgress is the latest progress reported for one image layer.
+type LayerProgress struct {
+	Status  string `json:"status"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+}
+
+// JobInfo is a point-in-time view of a job.
+type JobInfo struct {
+	ID         string                   `json:"id"`
+	Kind       string                   `json:"kind"` // pull, build, push or mirror
+	Host       string                   `json:"host"`
+	Target     string                   `json:"target"` // Image the job works on
+	Actor      string                   `json:"actor"`  // User, token or client address that started the job
+	Status     JobStatus                `json:"status"`
+	Error      string                   `json:"error,omitempty"`
+	Result     string                   `json:"result,omitempty"` // e.g. the ID of a built image
+	Layers     map[string]LayerProgress `json:"layers,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+}
+
+// Job is a long-running image operation. It keeps every progress message so
+// that clients can follow it from the start whenever they connect.
+type Job struct {
+	id        string
+	kind      string
+	host      string
+	target    string
+	actor     string
+	createdAt time.Time
+	cancel    context.CancelFunc
+
+	mu         sync.Mutex
+	status     JobStatus
+	err        string
+	result     string
+	finishedAt time.Time
+	messages   []jsonmessage.JSONMessage
+	layers     map[string]LayerProgress
+	changed    chan struct{} // Closed and replaced whenever the job changes
+}
+
+// ID returns the job ID.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Info returns the current state of the job.
+func (j *Job) Info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	info := JobInfo{
+		ID:        j.id,
+		Kind:      j.kind,
+		Host:      j.host,
+		Target:    j.target,
+		Actor:     j.actor,
+		Status:    j.status,
+		Error:     j.err,
+		Result:    j.result,
+		CreatedAt: j.createdAt,
+	}
+	if len(j.layers) > 0 {
+		info.Layers = map[string]LayerProgress{}
+		for id, layer := range j.layers {
+			info.Layers[id] = layer
+		}
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		info.FinishedAt = &finishedAt
+	}
+	return info
+}
+
+// Progress records one progress message. It is passed to the image operations as their progress callback.
+func (j *Job) Progress(message jsonmessage.JSONMessage) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.messages = append(j.messages, message)
+	if message.ID != "" && message.Status != "" {
+		layer := LayerProgress{Status: message.Status}
+		if message.Progress != nil {
+			layer.Current = message.Progress.Current
+			layer.Total = message.Progress.Total
+		}
+		j.layers[message.ID] = layer
+	}
+	j.notify()
+}
+
+// SetResult records the outcome of a successful job, such as a built image ID.
+func (j *Job) SetResult(result string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.result = result
+	j.notify()
+}
+
+func (j *Job) finish(err error, cancelled bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finishedAt = time.Now().UTC()
+	switch {
+	case cancelled:
+		j.status = JobCancelled
+		j.err = "job cancelled"
+	case err != nil:
+		j.status = JobFailed
+		j.err = err.Error()
+	default:
+		j.status = JobSucceeded
+	}
+	j.notify()
+}
+
+// notify wakes everyone following the job. The caller must hold j.mu.
+func (j *Job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Follow calls emit with every progress message, starting from the first,
+// until the job finishes or ctx is cancelled.
+func (j *Job) Follow(ctx context.Context, emit func(jsonmessage.JSONMessage) error) error {
+	next := 0
+	for {
+		j.mu.Lock()
+		pending := j.messages[next:]
+		next = len(j.messages)
+		finished := j.status != JobRunning
+		changed := j.changed
+		j.mu.Unlock()
+
+		for _, message := range pending {
+			if err := emit(message); err != nil {
+				return err
+			}
+		}
+		if finished {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// JobManager runs background jobs and keeps them for polling until they expire.
+type JobManager struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewJobManager creates an empty JobManager.
+func NewJobManager() *JobManager {
+	return &JobManager{jobs: map[string]*Job{}}
+}
+
+// Start runs fn in the background as a new job started by actor. The context
+// passed to fn is cancelled by Cancel, not by the request that started the job.
+func (m *JobManager) Start(kind, host, target, actor string, fn func(ctx context.Context, job *Job) error) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		id:        newJobID(),
+		kind:      kind,
+		host:      host,
+		target:    target,
+		actor:     actor,
+		createdAt: time.Now().UTC(),
+		cancel:    cancel,
+		status:    JobRunning,
+		layers:    map[string]LayerProgress{},
+		changed:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.removeExpired()
+	m.jobs[job.id] = job
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		err := fn(ctx, job)
+		job.finish(err, errors.Is(ctx.Err(), context.Canceled))
+	}()
+
+	return job
+}
+
+// Get returns the job with the given ID.
+func (m *JobManager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, &NotFoundError{Kind: "job", ID: id}
+	}
+	return job, nil
+}
+
+// List describes every job, newest first.
+func (m *JobManager) List() []JobInfo {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.Unlock()
+
+	infos := []JobInfo{}
+	for _, job := range jobs {
+		infos = append(infos, job.Info())
+	}
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].CreatedAt.After(infos[k].CreatedAt)
+	})
+	return infos
+}
+
+// Cancel stops a running job.
+func (m *JobManager) Cancel(id string) error {
+	job, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	job.cancel()
+	return nil
+}
+
+// removeExpired forgets jobs that finished more than jobRetention ago. The caller must hold m.mu.
+func (m *JobManager) removeExpired() {
+	for id, job := range m.jobs {
+		job.mu.Lock()
+		expired := !job.finishedAt.IsZero() && time.Since(job.finishedAt) > jobRetention
+		job.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
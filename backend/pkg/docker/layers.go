@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// ImageLayer is one entry of an image's history.
+type ImageLayer struct {
+	ID        string    `json:"id"` // "<missing>" for layers built elsewhere
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Size      int64     `json:"size_bytes"`
+	Tags      []string  `json:"tags,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+}
+
+// ImageHistory lists the layers of an image, newest first, with the command that created each.
+func (s *DockerService) ImageHistory(ctx context.Context, imageID string) ([]ImageLayer, error) {
+	history, err := s.cli.ImageHistory(ctx, imageID)
+	if err != nil {
+		return nil, notFound(err, "image", imageID)
+	}
+
+	layers := []ImageLayer{}
+	for _, item := range history {
+		layers = append(layers, ImageLayer{
+			ID:        item.ID,
+			CreatedBy: item.CreatedBy,
+			Created:   time.Unix(item.Created, 0).UTC(),
+			Size:      item.Size,
+			Tags:      item.Tags,
+			Comment:   item.Comment,
+		})
+	}
+	return layers, nil
+}
+
+// ImageUsage splits the disk usage of one image into layers shared with other
+// images and layers only it uses.
+type ImageUsage struct {
+	ID         string   `json:"id"`
+	Tags       []string `json:"tags"`
+	Size       int64    `json:"size_bytes"`        // Including shared layers
+	SharedSize int64    `json:"shared_size_bytes"` // Layers also used by other images
+	UniqueSize int64    `json:"unique_size_bytes"` // Freed if this image alone is removed
+	Containers int64    `json:"containers"`
+}
+
+// BaseImageUsage is a local image whose layers other local images build on.
+type BaseImageUsage struct {
+	ID         string   `json:"id"`
+	Tags       []string `json:"tags"`
+	Layers     int      `json:"layers"`            // Number of layers the images share with it
+	SharedSize int64    `json:"shared_size_bytes"` // Size of those layers, stored once however many images use them
+	Images     []string `json:"images"`            // IDs of the images built on it
+}
+
+// ImageUsageReport is the disk usage of every local image.
+type ImageUsageReport struct {
+	LayersSize int64            `json:"layers_size_bytes"` // Actual disk usage, shared layers counted once
+	TotalSize  int64            `json:"total_size_bytes"`  // Sum of image sizes, shared layers counted per image
+	Images     []ImageUsage     `json:"images"`            // Largest shared size first, so heavy base images come first
+	BaseImages []BaseImageUsage `json:"base_images"`       // Most bytes saved by sharing first
+}
+
+// ImageUsage reports the shared and unique size of every local image, and the
+// base images whose layers they share.
+func (s *DockerService) ImageUsage(ctx context.Context) (ImageUsageReport, error) {
+	usage, err := s.cli.DiskUsage(ctx)
+	if err != nil {
+		return ImageUsageReport{}, err
+	}
+
+	report := ImageUsageReport{LayersSize: usage.LayersSize, Images: []ImageUsage{}}
+	layers := map[string][]string{}
+	for _, image := range usage.Images {
+		// Disk usage does not list layers, so read them from each image. One
+		// removed since the disk usage was read is left out of the base images.
+		if inspected, _, err := s.cli.ImageInspectWithRaw(ctx, image.ID); err == nil {
+			layers[image.ID] = inspected.RootFS.Layers
+		}
+
+		sharedSize := image.SharedSize
+		if sharedSize < 0 {
+			// Not computed by the daemon
+			sharedSize = 0
+		}
+		report.TotalSize += image.Size
+		report.Images = append(report.Images, ImageUsage{
+			ID:         image.ID,
+			Tags:       image.RepoTags,
+			Size:       image.Size,
+			SharedSize: sharedSize,
+			UniqueSize: image.Size - sharedSize,
+			Containers: image.Containers,
+		})
+	}
+
+	sort.Slice(report.Images, func(i, j int) bool {
+		if report.Images[i].SharedSize != report.Images[j].SharedSize {
+			return report.Images[i].SharedSize > report.Images[j].SharedSize
+		}
+		return report.Images[i].Size > report.Images[j].Size
+	})
+	report.BaseImages = baseImages(report.Images, layers)
+
+	return report, nil
+}
+
+// baseImages finds the images whose layers, given by image ID, are a proper
+// prefix of the layers of other images. The layers of a base image are the
+// whole of it, so its size is what the images built on it share.
+func baseImages(images []ImageUsage, layers map[string][]string) []BaseImageUsage {
+	bases := []BaseImageUsage{}
+	for _, base := range images {
+		baseLayers := layers[base.ID]
+		if len(baseLayers) == 0 {
+			continue
+		}
+
+		usage := BaseImageUsage{ID: base.ID, Tags: base.Tags, Layers: len(baseLayers), SharedSize: base.Size, Images: []string{}}
+		for _, image := range images {
+			if imageLayers := layers[image.ID]; len(imageLayers) > len(baseLayers) && hasLayerPrefix(imageLayers, baseLayers) {
+				usage.Images = append(usage.Images, image.ID)
+			}
+		}
+		if len(usage.Images) > 0 {
+			bases = append(bases, usage)
+		}
+	}
+
+	saved := func(base BaseImageUsage) int64 {
+		return base.SharedSize * int64(len(base.Images))
+	}
+	sort.SliceStable(bases, func(i, j int) bool {
+		return saved(bases[i]) > saved(bases[j])
+	})
+	return bases
+}
+
+// hasLayerPrefix reports whether layers starts with prefix.
+func hasLayerPrefix(layers, prefix []string) bool {
+	for i, layer := range prefix {
+		if layers[i] != layer {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package docker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBaseImages(t *testing.T) {
+	images := []ImageUsage{
+		{ID: "alpine", Size: 5},
+		{ID: "python", Size: 50},
+		{ID: "app", Size: 60},
+		{ID: "worker", Size: 55},
+		{ID: "debian", Size: 80},
+		{ID: "unread", Size: 10},
+	}
+	layers := map[string][]string{
+		"alpine": {"a"},
+		"python": {"a", "p"},
+		"app":    {"a", "p", "x"},
+		"worker": {"a", "p", "w"},
+		"debian": {"d"},
+	}
+
+	bases := baseImages(images, layers)
+	got := []string{}
+	for _, base := range bases {
+		got = append(got, fmt.Sprintf("%s:%d:%d:%v", base.ID, base.Layers, base.SharedSize, base.Images))
+	}
+	// python saves 2x50 bytes, alpine 3x5; debian and the leaf images are no one's base
+	want := []string{"python:2:50:[app worker]", "alpine:1:5:[python app worker]"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("baseImages = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// ResultCode identifies the outcome of a container state change.
+type ResultCode string
+
+const (
+	ResultStarted   ResultCode = "started"
+	ResultStopped   ResultCode = "stopped"
+	ResultRestarted ResultCode = "restarted"
+	ResultPaused    ResultCode = "paused"
+	ResultUnpaused  ResultCode = "unpaused"
+	ResultKilled    ResultCode = "killed"
+	ResultRenamed   ResultCode = "renamed"
+	ResultExited    ResultCode = "exited" // The container is not running afterwards; see ExitCode
+
+	// Refusals, reported as the Code of a *StateError
+	ResultAlreadyRunning ResultCode = "already_running"
+	ResultNotRunning     ResultCode = "not_running"
+	ResultAlreadyPaused  ResultCode = "already_paused"
+	ResultNotPaused      ResultCode = "not_paused"
+)
+
+// ActionResult reports the outcome of a container state change.
+type ActionResult struct {
+	Code     ResultCode `json:"code"`
+	Message  string     `json:"message"`
+	ExitCode *int       `json:"exit_code,omitempty"`
+}
+
+func newActionResult(code ResultCode, message string) ActionResult {
+	return ActionResult{Code: code, Message: message}
+}
+
+// exitedResult reports a container that is not running after the action.
+func exitedResult(prefix string, exitCode int) ActionResult {
+	message := prefix + ", it exited immediately"
+	if exitCode != 0 {
+		message = fmt.Sprintf("%s, it exited with code %d", prefix, exitCode)
+	}
+	return ActionResult{Code: ResultExited, Message: message, ExitCode: &exitCode}
+}
+
+// inspectForAction looks up a container before changing its state.
+func (s *DockerService) inspectForAction(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	containerJSON, err := s.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return types.ContainerJSON{}, notFound(err, "container", containerID)
+	}
+	return containerJSON, nil
+}
+
+// RestartContainer stops and starts a container. A running container is given
+// timeout to exit before it is killed; a nil timeout uses its own stop timeout.
+func (s *DockerService) RestartContainer(ctx context.Context, containerID string, timeout *time.Duration) (ActionResult, error) {
+	// Check that the container exists
+	if _, err := s.inspectForAction(ctx, containerID); err != nil {
+		return ActionResult{}, err
+	}
+
+	// Restart the container
+	if err := s.cli.ContainerRestart(ctx, containerID, timeout); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to restart the container: %w", Classify(err))
+	}
+
+	// Check the container's state after restarting it
+	containerJSON, err := s.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	if !containerJSON.State.Running {
+		return exitedResult("Failed to restart the container", containerJSON.State.ExitCode), nil
+	}
+
+	return newActionResult(ResultRestarted, "Container restarted successfully"), nil
+}
+
+// PauseContainer freezes every process in a running container.
+func (s *DockerService) PauseContainer(ctx context.Context, containerID string) (ActionResult, error) {
+	// Check the current status of the container
+	containerJSON, err := s.inspectForAction(ctx, containerID)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	if !containerJSON.State.Running {
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultNotRunning, Message: "Specified container is not running"}
+	}
+	if containerJSON.State.Paused {
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultAlreadyPaused, Message: "Container is already paused"}
+	}
+
+	// Pause the container
+	if err := s.cli.ContainerPause(ctx, containerID); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to pause the container: %w", Classify(err))
+	}
+
+	return newActionResult(ResultPaused, "Container paused successfully"), nil
+}
+
+// UnpauseContainer resumes a paused container.
+func (s *DockerService) UnpauseContainer(ctx context.Context, containerID string) (ActionResult, error) {
+	// Check the current status of the container
+	containerJSON, err := s.inspectForAction(ctx, containerID)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	if !containerJSON.State.Paused {
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultNotPaused, Message: "Specified container is not paused"}
+	}
+
+	// Unpause the container
+	if err := s.cli.ContainerUnpause(ctx, containerID); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to unpause the container: %w", Classify(err))
+	}
+
+	return newActionResult(ResultUnpaused, "Container unpaused successfully"), nil
+}
+
+// KillContainer sends signal to the main process of a running container.
+// An empty signal sends SIGKILL.
+func (s *DockerService) KillContainer(ctx context.Context, containerID string, signal string) (ActionResult, error) {
+	// Check the current status of the container
+	containerJSON, err := s.inspectForAction(ctx, containerID)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	if !containerJSON.State.Running {
+		return ActionResult{}, &StateError{ContainerID: containerID, Code: ResultNotRunning, Message: "Specified container is not running"}
+	}
+
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+
+	// Send the signal
+	if err := s.cli.ContainerKill(ctx, containerID, signal); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to kill the container: %w", Classify(err))
+	}
+
+	return newActionResult(ResultKilled, fmt.Sprintf("Sent %s to the container", signal)), nil
+}
+
+// RenameContainer gives a container a new name.
+func (s *DockerService) RenameContainer(ctx context.Context, containerID string, newName string) (ActionResult, error) {
+	newName = strings.TrimPrefix(newName, "/")
+	if newName == "" {
+		return ActionResult{}, invalidInput("new container name is required")
+	}
+
+	// Check that the container exists
+	if _, err := s.inspectForAction(ctx, containerID); err != nil {
+		return ActionResult{}, err
+	}
+
+	// Rename the container
+	if err := s.cli.ContainerRename(ctx, containerID, newName); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to rename the container: %w", Classify(err))
+	}
+
+	return newActionResult(ResultRenamed, "Container renamed to "+newName), nil
+}
+
+// WaitContainer blocks until the container reaches condition (not-running,
+// next-exit or removed; default not-running) and reports its exit code.
+func (s *DockerService) WaitContainer(ctx context.Context, containerID string, condition string) (ActionResult, error) {
+	waitCondition := container.WaitCondition(condition)
+	switch waitCondition {
+	case container.WaitConditionNotRunning, container.WaitConditionNextExit, container.WaitConditionRemoved:
+	case "":
+		waitCondition = container.WaitConditionNotRunning
+	default:
+		return ActionResult{}, invalidInput("invalid wait condition: %s", condition)
+	}
+
+	// Check that the container exists
+	if _, err := s.inspectForAction(ctx, containerID); err != nil {
+		return ActionResult{}, err
+	}
+
+	results, errs := s.cli.ContainerWait(ctx, containerID, waitCondition)
+	select {
+	case result := <-results:
+		if result.Error != nil && result.Error.Message != "" {
+			return ActionResult{}, errors.New("failed to wait for the container: " + result.Error.Message)
+		}
+		exitCode := int(result.StatusCode)
+		return ActionResult{
+			Code:     ResultExited,
+			Message:  fmt.Sprintf("Container exited with code %d", exitCode),
+			ExitCode: &exitCode,
+		}, nil
+	case err := <-errs:
+		return ActionResult{}, fmt.Errorf("failed to wait for the container: %w", Classify(err))
+	}
+}
@@ -2,52 +2,26 @@ package docker
 
 import (
 	"context"
-	"errors"
-	"fmt"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/api/types/filters"
 )
 
-// ListNetworks retrieves all Docker networks with ID, Name, Driver, and Scope
-func ListNetworks() ([]map[string]string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// ListNetworks lists the networks matching filter
+func (s *DockerService) ListNetworks(ctx context.Context, filter filters.Args) ([]types.NetworkResource, error) {
+	networks, err := s.cli.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
 	if err != nil {
-		return nil, err
-	}
-
-	// List all networks
-	networks, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	// Format response to include ID, Name, Driver, and Scope
-	networkDetails := []map[string]string{}
-	for _, network := range networks {
-		networkDetails = append(networkDetails, map[string]string{
-			"id":     network.ID,
-			"name":   network.Name,
-			"driver": network.Driver,
-			"scope":  network.Scope,
-		})
+		return nil, Classify(err)
 	}
 
-	return networkDetails, nil
+	return networks, nil
 }
 
-func InspectNetwork(networkID string) (map[string]interface{}, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+func (s *DockerService) InspectNetwork(ctx context.Context, networkID string) (map[string]interface{}, error) {
 	// Inspect the specified network
-	networkResource, err := cli.NetworkInspect(context.Background(), networkID, types.NetworkInspectOptions{})
+	networkResource, err := s.cli.NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
 	if err != nil {
-		return nil, errors.New("network not found")
+		return nil, notFound(err, "network", networkID)
 	}
 
 	// Prepare the network details in response format
@@ -67,15 +41,9 @@ type NetworkContainer struct {
 	Name        string `json:"name"`
 }
 
-func ListContainersInNetwork(networkID string) ([]NetworkContainer, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+func (s *DockerService) ListContainersInNetwork(ctx context.Context, networkID string) ([]NetworkContainer, error) {
 	// Inspect the network to retrieve details of attached containers
-	networkResource, err := cli.NetworkInspect(context.Background(), networkID, types.NetworkInspectOptions{})
+	networkResource, err := s.cli.NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -89,29 +57,25 @@ func ListContainersInNetwork(networkID string) ([]NetworkContainer, error) {
 		})
 	}
 
-	// Return an error message if no containers are attached
-	if len(containers) == 0 {
-		return nil, fmt.Errorf("No containers are attached to the network: %s", networkID)
-	}
-
 	return containers, nil
 }
 
-func RemoveNetwork(networkID string) (string, error) {
-    // Create a new Docker client
-    cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-    if err != nil {
-        return "", err
-    }
+// RemoveNetwork removes a network unless protection refuses it
+func (s *DockerService) RemoveNetwork(ctx context.Context, networkID string, protection *ProtectionPolicy) (string, error) {
+	// Refuse to remove a protected network
+	network, err := s.cli.NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
+	if err != nil {
+		return "", notFound(err, "network", networkID)
+	}
+	if err := protection.Check(Resource{Type: PruneNetworks, ID: network.ID, Names: []string{network.Name}, Labels: network.Labels}); err != nil {
+		return "", err
+	}
 
-    // Try to remove the network
-    if err := cli.NetworkRemove(context.Background(), networkID); err != nil {
-        // Handle network not found error
-        if client.IsErrNotFound(err) {
-            return "", errors.New("Network not found. Invalid Network ID.")
-        }
-        return "", err
-    }
+	// Try to remove the network
+	if err := s.cli.NetworkRemove(ctx, networkID); err != nil {
+		// Handle network not found error
+		return "", notFound(err, "network", networkID)
+	}
 
-    return "Network removed successfully", nil
-}
\ No newline at end of file
+	return "Network removed successfully", nil
+}
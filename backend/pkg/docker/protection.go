@@ -0,0 +1,154 @@
+package docker
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"Docker_Management/pkg/config"
+)
+
+// Resource identifies a container, image, volume or network for the protection policy.
+type Resource struct {
+	Type   PruneType
+	ID     string
+	Names  []string // Container, volume or network names, or image tags
+	Labels map[string]string
+}
+
+// ProtectedError reports a deletion refused by the protection policy.
+type ProtectedError struct {
+	Resource Resource
+	Rule     string
+}
+
+func (e *ProtectedError) Error() string {
+	return fmt.Sprintf("%s %s is protected by rule %q; set override and give a reason to remove it",
+		strings.TrimSuffix(string(e.Resource.Type), "s"), e.Resource.displayName(), e.Rule)
+}
+
+// displayName is the first name of the resource, or its ID.
+func (r Resource) displayName() string {
+	if len(r.Names) > 0 {
+		return r.Names[0]
+	}
+	return r.ID
+}
+
+// SkippedResource is a resource a bulk removal left alone because it is protected.
+type SkippedResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Rule string `json:"rule"`
+}
+
+// protectionRule is the parsed form of a config.ProtectionRule.
+type protectionRule struct {
+	name      string
+	types     map[PruneType]bool
+	labels    []labelSelector
+	names     []string
+	resources map[string]bool
+}
+
+// ProtectionPolicy decides which resources may not be deleted. A nil policy protects nothing.
+type ProtectionPolicy struct {
+	rules []protectionRule
+}
+
+// NewProtectionPolicy validates the configured rules.
+func NewProtectionPolicy(rules []config.ProtectionRule) (*ProtectionPolicy, error) {
+	policy := &ProtectionPolicy{}
+	for i, rule := range rules {
+		parsed := protectionRule{
+			name:      rule.Name,
+			types:     map[PruneType]bool{},
+			names:     rule.Names,
+			resources: map[string]bool{},
+		}
+		if parsed.name == "" {
+			parsed.name = fmt.Sprintf("rule %d", i+1)
+		}
+		for _, resourceType := range rule.Types {
+			switch PruneType(resourceType) {
+			case PruneContainers, PruneImages, PruneVolumes, PruneNetworks:
+				parsed.types[PruneType(resourceType)] = true
+			default:
+				return nil, fmt.Errorf("protection rule %s: unknown resource type %q", parsed.name, resourceType)
+			}
+		}
+		for _, label := range rule.Labels {
+			selector, err := parseLabelSelector(label)
+			if err != nil {
+				return nil, fmt.Errorf("protection rule %s: %v", parsed.name, err)
+			}
+			parsed.labels = append(parsed.labels, selector)
+		}
+		for _, glob := range rule.Names {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("protection rule %s: invalid name glob %q", parsed.name, glob)
+			}
+		}
+		for _, resource := range rule.Resources {
+			parsed.resources[resource] = true
+		}
+		policy.rules = append(policy.rules, parsed)
+	}
+	return policy, nil
+}
+
+// Check returns a *ProtectedError if the resource is protected.
+func (p *ProtectionPolicy) Check(resource Resource) error {
+	if p == nil {
+		return nil
+	}
+	for _, rule := range p.rules {
+		if rule.matches(resource) {
+			return &ProtectedError{Resource: resource, Rule: rule.name}
+		}
+	}
+	return nil
+}
+
+// skip reports whether a bulk removal must leave the resource alone, and records it in skipped.
+func (p *ProtectionPolicy) skip(resource Resource, skipped *[]SkippedResource) bool {
+	err := p.Check(resource)
+	if err == nil {
+		return false
+	}
+	*skipped = append(*skipped, SkippedResource{
+		ID:   resource.ID,
+		Name: resource.displayName(),
+		Rule: err.(*ProtectedError).Rule,
+	})
+	return true
+}
+
+func (r protectionRule) matches(resource Resource) bool {
+	if len(r.types) > 0 && !r.types[resource.Type] {
+		return false
+	}
+
+	// Explicit resources match the full ID, the short ID or any name
+	id := strings.TrimPrefix(resource.ID, "sha256:")
+	if r.resources[resource.ID] || r.resources[id] || (len(id) >= 12 && r.resources[id[:12]]) {
+		return true
+	}
+	for _, name := range resource.Names {
+		if r.resources[name] {
+			return true
+		}
+		for _, glob := range r.names {
+			if matched, _ := path.Match(glob, name); matched {
+				return true
+			}
+		}
+	}
+
+	for _, selector := range r.labels {
+		if selector.matches(resource.Labels) {
+			return true
+		}
+	}
+	return false
+}
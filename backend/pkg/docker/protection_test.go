@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"Docker_Management/pkg/config"
+)
+
+func TestProtectionPolicyCheck(t *testing.T) {
+	policy, err := NewProtectionPolicy([]config.ProtectionRule{
+		{Name: "databases", Types: []string{"images"}, Names: []string{"postgres:*"}},
+		{Name: "kept", Labels: []string{"keep=true"}},
+		{Name: "pinned", Resources: []string{"vault", "0123456789ab"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		resource Resource
+		rule     string // Empty when the resource is not protected
+	}{
+		{Resource{Type: PruneImages, ID: "sha256:aaa", Names: []string{"postgres:16"}}, "databases"},
+		{Resource{Type: PruneContainers, ID: "c1", Names: []string{"postgres:16"}}, ""},
+		{Resource{Type: PruneImages, ID: "sha256:bbb", Names: []string{"redis:7"}}, ""},
+		{Resource{Type: PruneVolumes, ID: "data", Labels: map[string]string{"keep": "true"}}, "kept"},
+		{Resource{Type: PruneVolumes, ID: "scratch", Labels: map[string]string{"keep": "false"}}, ""},
+		{Resource{Type: PruneContainers, ID: "c2", Names: []string{"vault"}}, "pinned"},
+		{Resource{Type: PruneImages, ID: "sha256:0123456789abcdef"}, "pinned"},
+		{Resource{Type: PruneNetworks, ID: "n1", Names: []string{"vault-net"}}, ""},
+	}
+	for _, test := range tests {
+		err := policy.Check(test.resource)
+		var protected *ProtectedError
+		switch {
+		case test.rule == "" && err != nil:
+			t.Errorf("Check(%+v) = %v, want nil", test.resource, err)
+		case test.rule != "" && !errors.As(err, &protected):
+			t.Errorf("Check(%+v) = %v, want ProtectedError", test.resource, err)
+		case test.rule != "" && protected.Rule != test.rule:
+			t.Errorf("Check(%+v) matched rule %q, want %q", test.resource, protected.Rule, test.rule)
+		}
+	}
+
+	var none *ProtectionPolicy
+	if err := none.Check(Resource{Type: PruneVolumes, ID: "data"}); err != nil {
+		t.Errorf("nil policy Check = %v, want nil", err)
+	}
+}
+
+func TestNewProtectionPolicyRejectsInvalidRules(t *testing.T) {
+	invalid := []config.ProtectionRule{
+		{Types: []string{"everything"}},
+		{Labels: []string{"=a"}},
+		{Names: []string{"postgres:["}},
+	}
+	for _, rule := range invalid {
+		if _, err := NewProtectionPolicy([]config.ProtectionRule{rule}); err == nil {
+			t.Errorf("NewProtectionPolicy(%+v) = nil error, want an error", rule)
+		}
+	}
+}
@@ -0,0 +1,600 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// PruneType names a kind of resource that can be pruned.
+type PruneType string
+
+const (
+	PruneContainers PruneType = "containers"
+	PruneImages     PruneType = "images"
+	PruneVolumes    PruneType = "volumes"
+	PruneNetworks   PruneType = "networks"
+	PruneBuildCache PruneType = "build_cache"
+)
+
+// PruneTypes lists every prunable resource type.
+var PruneTypes = []PruneType{PruneContainers, PruneImages, PruneVolumes, PruneNetworks, PruneBuildCache}
+
+// PruneOptions selects what a prune removes. Without filters it removes what
+// "docker <type> prune" would: stopped containers, dangling images, unused
+// volumes, unused networks and unused build cache.
+type PruneOptions struct {
+	Types     []PruneType `json:"types"`      // Empty means every type
+	DryRun    bool        `json:"dry_run"`    // Report what would be removed without removing it
+	Until     string      `json:"until"`      // Only resources created before this, as a duration (24h) or RFC3339 timestamp
+	Labels    []string    `json:"labels"`     // key, key=value, !key or !key=value; all must match
+	AllImages bool        `json:"all_images"` // Remove every unused image, not only dangling ones
+	KeepLast  int         `json:"keep_last"`  // Keep the newest N tagged images of each repository, counting those in use; implies AllImages
+}
+
+// PruneItem is one resource that was, or would be, removed.
+type PruneItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size_bytes"` // Space freed by removing it, 0 if unknown
+}
+
+// PruneFailure is a resource that could not be removed.
+type PruneFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// PruneResult is the outcome of pruning one resource type.
+type PruneResult struct {
+	Type           PruneType         `json:"type"`
+	Items          []PruneItem       `json:"items"`
+	Skipped        []SkippedResource `json:"skipped,omitempty"` // Protected resources left alone
+	Failed         []PruneFailure    `json:"failed,omitempty"`
+	SpaceReclaimed uint64            `json:"space_reclaimed_bytes"`
+	NotPruned      string            `json:"not_pruned,omitempty"` // Why the whole type was left alone
+}
+
+// PruneReport is the outcome of a prune across resource types.
+type PruneReport struct {
+	DryRun         bool          `json:"dry_run"`
+	Results        []PruneResult `json:"results"`
+	SpaceReclaimed uint64        `json:"space_reclaimed_bytes"`
+}
+
+// pruneFilter is the parsed form of the filters in PruneOptions.
+type pruneFilter struct {
+	until  time.Time
+	labels []labelSelector
+}
+
+// labelSelector matches a label key, and its value when value is set.
+type labelSelector struct {
+	key      string
+	value    string
+	hasValue bool
+	negate   bool
+}
+
+func (l labelSelector) String() string {
+	if l.hasValue {
+		return l.key + "=" + l.value
+	}
+	return l.key
+}
+
+// parseLabelSelector parses key, key=value, !key or !key=value.
+func parseLabelSelector(label string) (labelSelector, error) {
+	var selector labelSelector
+	selector.negate = strings.HasPrefix(label, "!")
+	selector.key, selector.value, selector.hasValue = strings.Cut(strings.TrimPrefix(label, "!"), "=")
+	if selector.key == "" {
+		return selector, invalidInput("invalid label selector %q", label)
+	}
+	return selector, nil
+}
+
+// matches reports whether labels satisfy the selector.
+func (l labelSelector) matches(labels map[string]string) bool {
+	value, ok := labels[l.key]
+	found := ok && (!l.hasValue || value == l.value)
+	return found != l.negate
+}
+
+// matches reports whether a resource passes the age and label filters.
+func (f pruneFilter) matches(labels map[string]string, created time.Time) bool {
+	if !f.until.IsZero() && !created.Before(f.until) {
+		return false
+	}
+	for _, selector := range f.labels {
+		if !selector.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// args converts the filter for the daemon's prune APIs.
+func (f pruneFilter) args(withUntil bool) filters.Args {
+	args := filters.NewArgs()
+	if withUntil && !f.until.IsZero() {
+		args.Add("until", f.until.Format(time.RFC3339Nano))
+	}
+	for _, selector := range f.labels {
+		if selector.negate {
+			args.Add("label!", selector.String())
+		} else {
+			args.Add("label", selector.String())
+		}
+	}
+	return args
+}
+
+// parsePruneFilter validates the filters in opts.
+func parsePruneFilter(opts PruneOptions) (pruneFilter, error) {
+	var filter pruneFilter
+	if opts.Until != "" {
+		if duration, err := time.ParseDuration(opts.Until); err == nil {
+			filter.until = time.Now().Add(-duration)
+		} else if timestamp, err := time.Parse(time.RFC3339, opts.Until); err == nil {
+			filter.until = timestamp
+		} else {
+			return filter, invalidInput("invalid until %q: expected a duration such as 24h or an RFC3339 timestamp", opts.Until)
+		}
+	}
+
+	for _, label := range opts.Labels {
+		selector, err := parseLabelSelector(label)
+		if err != nil {
+			return filter, err
+		}
+		filter.labels = append(filter.labels, selector)
+	}
+
+	if opts.KeepLast < 0 {
+		return filter, invalidInput("keep_last must not be negative")
+	}
+	for _, pruneType := range opts.Types {
+		if !validPruneType(pruneType) {
+			return filter, invalidInput("unknown prune type: %s", pruneType)
+		}
+		// Build cache has no labels, so the selectors could not narrow it down
+		if pruneType == PruneBuildCache && len(filter.labels) > 0 {
+			return filter, invalidInput("label filters do not apply to build cache; prune it separately")
+		}
+	}
+	return filter, nil
+}
+
+// ValidatePruneOptions reports invalid filters or types in opts.
+func ValidatePruneOptions(opts PruneOptions) error {
+	_, err := parsePruneFilter(opts)
+	return err
+}
+
+func validPruneType(pruneType PruneType) bool {
+	for _, known := range PruneTypes {
+		if pruneType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune removes unused resources of the requested types, or with DryRun set,
+// reports exactly what would be removed and how much space that frees.
+// Resources protected by protection are never removed; the daemon's prune APIs
+// cannot exclude them, so a type with protected candidates is removed one by one.
+func (s *DockerService) Prune(ctx context.Context, opts PruneOptions, protection *ProtectionPolicy) (PruneReport, error) {
+	filter, err := parsePruneFilter(opts)
+	if err != nil {
+		return PruneReport{}, err
+	}
+
+	pruneTypes := opts.Types
+	if len(pruneTypes) == 0 {
+		pruneTypes = PruneTypes
+	}
+
+	report := PruneReport{DryRun: opts.DryRun, Results: []PruneResult{}}
+	for _, pruneType := range pruneTypes {
+		var result PruneResult
+		switch pruneType {
+		case PruneContainers:
+			result, err = s.pruneContainers(ctx, filter, opts.DryRun, protection)
+		case PruneImages:
+			result, err = s.pruneImages(ctx, filter, opts, protection)
+		case PruneVolumes:
+			result, err = s.pruneVolumes(ctx, filter, opts.DryRun, protection)
+		case PruneNetworks:
+			result, err = s.pruneNetworks(ctx, filter, opts.DryRun, protection)
+		case PruneBuildCache:
+			if len(filter.labels) > 0 {
+				// Only reached when every type was requested; see parsePruneFilter
+				result = PruneResult{Items: []PruneItem{}, NotPruned: "label filters do not apply to build cache"}
+				break
+			}
+			result, err = s.pruneBuildCache(ctx, filter, opts.DryRun)
+		default:
+			return report, fmt.Errorf("unknown prune type: %s", pruneType)
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to prune %s: %w", pruneType, Classify(err))
+		}
+		result.Type = pruneType
+		report.Results = append(report.Results, result)
+		report.SpaceReclaimed += result.SpaceReclaimed
+	}
+
+	return report, nil
+}
+
+// estimate fills in a dry-run result from its candidates.
+func estimate(candidates []PruneItem) PruneResult {
+	result := PruneResult{Items: candidates}
+	for _, item := range candidates {
+		if item.Size > 0 {
+			result.SpaceReclaimed += uint64(item.Size)
+		}
+	}
+	return result
+}
+
+// removeEach removes candidates one by one, for selections the daemon's prune
+// APIs cannot express.
+func removeEach(candidates []PruneItem, remove func(id string) error) PruneResult {
+	result := PruneResult{Items: []PruneItem{}}
+	for _, item := range candidates {
+		if err := remove(item.ID); err != nil {
+			result.Failed = append(result.Failed, PruneFailure{ID: item.ID, Error: err.Error()})
+			continue
+		}
+		result.Items = append(result.Items, item)
+		if item.Size > 0 {
+			result.SpaceReclaimed += uint64(item.Size)
+		}
+	}
+	return result
+}
+
+// deleted picks the candidates the daemon reports as deleted, keeping any it
+// deleted that were not candidates.
+func deleted(candidates []PruneItem, ids []string) []PruneItem {
+	byID := map[string]PruneItem{}
+	for _, item := range candidates {
+		byID[item.ID] = item
+	}
+
+	items := []PruneItem{}
+	for _, id := range ids {
+		item, ok := byID[id]
+		if !ok {
+			item = PruneItem{ID: id}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// pruneContainers removes stopped containers.
+func (s *DockerService) pruneContainers(ctx context.Context, filter pruneFilter, dryRun bool, protection *ProtectionPolicy) (PruneResult, error) {
+	containers, err := s.cli.ContainerList(ctx, types.ContainerListOptions{All: true, Size: true})
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	candidates := []PruneItem{}
+	skipped := []SkippedResource{}
+	for _, container := range containers {
+		// Matches the daemon, which prunes every container that is not running
+		if container.State == "running" || container.State == "paused" || container.State == "restarting" {
+			continue
+		}
+		if !filter.matches(container.Labels, time.Unix(container.Created, 0)) {
+			continue
+		}
+		names := containerNames(container)
+		if protection.skip(Resource{Type: PruneContainers, ID: container.ID, Names: names, Labels: container.Labels}, &skipped) {
+			continue
+		}
+		name := ""
+		if len(names) > 0 {
+			name = names[0]
+		}
+		candidates = append(candidates, PruneItem{ID: container.ID, Name: name, Size: container.SizeRw})
+	}
+
+	var result PruneResult
+	switch {
+	case dryRun:
+		result = estimate(candidates)
+	case len(skipped) > 0:
+		result = removeEach(candidates, func(id string) error {
+			return s.cli.ContainerRemove(ctx, id, types.ContainerRemoveOptions{})
+		})
+	default:
+		pruned, err := s.cli.ContainersPrune(ctx, filter.args(true))
+		if err != nil {
+			return PruneResult{}, err
+		}
+		result = PruneResult{Items: deleted(candidates, pruned.ContainersDeleted), SpaceReclaimed: pruned.SpaceReclaimed}
+	}
+	result.Skipped = skipped
+	return result, nil
+}
+
+// pruneImages removes dangling images, or every unused image with AllImages or
+// KeepLast. The daemon cannot keep the newest tags of a repository, so with
+// KeepLast the candidates are removed one by one.
+func (s *DockerService) pruneImages(ctx context.Context, filter pruneFilter, opts PruneOptions, protection *ProtectionPolicy) (PruneResult, error) {
+	usage, err := s.cli.DiskUsage(ctx)
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	all := opts.AllImages || opts.KeepLast > 0
+	images := usage.Images
+	if opts.KeepLast > 0 {
+		// Keep the newest of each repository whether or not they are in use,
+		// so that an image in use counts towards the images kept
+		images = dropNewestPerRepository(images, opts.KeepLast)
+	}
+
+	unused := []*types.ImageSummary{}
+	for _, image := range images {
+		if image.Containers > 0 {
+			continue
+		}
+		if !all && len(taggedReferences(image.RepoTags)) > 0 {
+			continue
+		}
+		if !filter.matches(image.Labels, time.Unix(image.Created, 0)) {
+			continue
+		}
+		unused = append(unused, image)
+	}
+	images = unused
+
+	candidates := []PruneItem{}
+	skipped := []SkippedResource{}
+	for _, image := range images {
+		if protection.skip(Resource{Type: PruneImages, ID: image.ID, Names: taggedReferences(image.RepoTags), Labels: image.Labels}, &skipped) {
+			continue
+		}
+		size := image.Size
+		if image.SharedSize > 0 {
+			size -= image.SharedSize
+		}
+		candidates = append(candidates, PruneItem{ID: image.ID, Name: strings.Join(taggedReferences(image.RepoTags), ", "), Size: size})
+	}
+	var result PruneResult
+	switch {
+	case opts.DryRun:
+		result = estimate(candidates)
+	case opts.KeepLast > 0 || len(skipped) > 0:
+		result = removeEach(candidates, func(id string) error {
+			// Forced, since an image tagged in several repositories cannot otherwise be
+			// removed by ID. Images with containers were excluded above.
+			_, err := s.cli.ImageRemove(ctx, id, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+			return err
+		})
+	default:
+		args := filter.args(true)
+		args.Add("dangling", fmt.Sprint(!all))
+		pruned, err := s.cli.ImagesPrune(ctx, args)
+		if err != nil {
+			return PruneResult{}, err
+		}
+		ids := []string{}
+		for _, item := range pruned.ImagesDeleted {
+			if item.Deleted != "" {
+				ids = append(ids, item.Deleted)
+			}
+		}
+		// Only top-level image IDs match the candidates; parent layers are reported by ID alone
+		result = PruneResult{Items: deleted(candidates, ids), SpaceReclaimed: pruned.SpaceReclaimed}
+	}
+	result.Skipped = skipped
+	return result, nil
+}
+
+// taggedReferences drops the "<none>:<none>" placeholder of untagged images.
+func taggedReferences(repoTags []string) []string {
+	tags := []string{}
+	for _, tag := range repoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// dropNewestPerRepository removes from images the keep newest images of each
+// repository. An image kept for one of its repositories is kept altogether.
+func dropNewestPerRepository(images []*types.ImageSummary, keep int) []*types.ImageSummary {
+	byRepository := map[string][]*types.ImageSummary{}
+	for _, image := range images {
+		seen := map[string]bool{}
+		for _, tag := range taggedReferences(image.RepoTags) {
+			repository := tag
+			if i := strings.LastIndex(tag, ":"); i > strings.LastIndex(tag, "/") {
+				repository = tag[:i]
+			}
+			if !seen[repository] {
+				seen[repository] = true
+				byRepository[repository] = append(byRepository[repository], image)
+			}
+		}
+	}
+
+	kept := map[string]bool{}
+	for _, repositoryImages := range byRepository {
+		sort.Slice(repositoryImages, func(i, k int) bool {
+			return repositoryImages[i].Created > repositoryImages[k].Created
+		})
+		for i := 0; i < keep && i < len(repositoryImages); i++ {
+			kept[repositoryImages[i].ID] = true
+		}
+	}
+
+	remaining := []*types.ImageSummary{}
+	for _, image := range images {
+		if !kept[image.ID] {
+			remaining = append(remaining, image)
+		}
+	}
+	return remaining
+}
+
+// pruneVolumes removes volumes no container uses. The daemon cannot filter
+// volumes by age, so with Until the candidates are removed one by one.
+func (s *DockerService) pruneVolumes(ctx context.Context, filter pruneFilter, dryRun bool, protection *ProtectionPolicy) (PruneResult, error) {
+	usage, err := s.cli.DiskUsage(ctx)
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	candidates := []PruneItem{}
+	skipped := []SkippedResource{}
+	for _, volume := range usage.Volumes {
+		if volume.UsageData != nil && volume.UsageData.RefCount > 0 {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, volume.CreatedAt)
+		if !filter.matches(volume.Labels, created) {
+			continue
+		}
+		if protection.skip(Resource{Type: PruneVolumes, ID: volume.Name, Names: []string{volume.Name}, Labels: volume.Labels}, &skipped) {
+			continue
+		}
+		item := PruneItem{ID: volume.Name, Name: volume.Name}
+		if volume.UsageData != nil {
+			item.Size = volume.UsageData.Size
+		}
+		candidates = append(candidates, item)
+	}
+	var result PruneResult
+	switch {
+	case dryRun:
+		result = estimate(candidates)
+	case !filter.until.IsZero() || len(skipped) > 0:
+		result = removeEach(candidates, func(name string) error {
+			return s.cli.VolumeRemove(ctx, name, false)
+		})
+	default:
+		pruned, err := s.cli.VolumesPrune(ctx, filter.args(false))
+		if err != nil {
+			return PruneResult{}, err
+		}
+		result = PruneResult{Items: deleted(candidates, pruned.VolumesDeleted), SpaceReclaimed: pruned.SpaceReclaimed}
+	}
+	result.Skipped = skipped
+	return result, nil
+}
+
+// pruneNetworks removes user-defined networks no container is connected to.
+func (s *DockerService) pruneNetworks(ctx context.Context, filter pruneFilter, dryRun bool, protection *ProtectionPolicy) (PruneResult, error) {
+	networks, err := s.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	candidates := []PruneItem{}
+	skipped := []SkippedResource{}
+	for _, network := range networks {
+		// The predefined networks are never pruned
+		if network.Name == "bridge" || network.Name == "host" || network.Name == "none" || network.Ingress {
+			continue
+		}
+		if !filter.matches(network.Labels, network.Created) {
+			continue
+		}
+		// The list does not include connected containers, so inspect each network
+		inspected, err := s.cli.NetworkInspect(ctx, network.ID, types.NetworkInspectOptions{})
+		if err != nil || len(inspected.Containers) > 0 {
+			continue
+		}
+		if protection.skip(Resource{Type: PruneNetworks, ID: network.ID, Names: []string{network.Name}, Labels: network.Labels}, &skipped) {
+			continue
+		}
+		candidates = append(candidates, PruneItem{ID: network.ID, Name: network.Name})
+	}
+	if dryRun || len(skipped) > 0 {
+		var result PruneResult
+		if dryRun {
+			result = estimate(candidates)
+		} else {
+			result = removeEach(candidates, func(id string) error {
+				return s.cli.NetworkRemove(ctx, id)
+			})
+		}
+		result.Skipped = skipped
+		return result, nil
+	}
+
+	pruned, err := s.cli.NetworksPrune(ctx, filter.args(true))
+	if err != nil {
+		return PruneResult{}, err
+	}
+	// The daemon reports pruned networks by name
+	byName := map[string]PruneItem{}
+	for _, item := range candidates {
+		byName[item.Name] = item
+	}
+	items := []PruneItem{}
+	for _, name := range pruned.NetworksDeleted {
+		item, ok := byName[name]
+		if !ok {
+			item = PruneItem{Name: name}
+		}
+		items = append(items, item)
+	}
+	return PruneResult{Items: items}, nil
+}
+
+// pruneBuildCache removes build cache records that are not in use. Build cache
+// has no labels, so Prune never calls it with label selectors.
+func (s *DockerService) pruneBuildCache(ctx context.Context, filter pruneFilter, dryRun bool) (PruneResult, error) {
+	usage, err := s.cli.DiskUsage(ctx)
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	candidates := []PruneItem{}
+	for _, record := range usage.BuildCache {
+		if record.InUse {
+			continue
+		}
+		lastUsed := record.CreatedAt
+		if record.LastUsedAt != nil {
+			lastUsed = *record.LastUsedAt
+		}
+		if !filter.until.IsZero() && !lastUsed.Before(filter.until) {
+			continue
+		}
+		item := PruneItem{ID: record.ID, Name: record.Description}
+		if !record.Shared {
+			// Shared records are still referenced elsewhere and free nothing
+			item.Size = record.Size
+		}
+		candidates = append(candidates, item)
+	}
+	if dryRun {
+		return estimate(candidates), nil
+	}
+
+	args := filters.NewArgs()
+	if !filter.until.IsZero() {
+		args.Add("until", time.Since(filter.until).Round(time.Second).String())
+	}
+	pruned, err := s.cli.BuildCachePrune(ctx, types.BuildCachePruneOptions{All: true, Filters: args})
+	if err != nil {
+		return PruneResult{}, err
+	}
+	return PruneResult{Items: deleted(candidates, pruned.CachesDeleted), SpaceReclaimed: pruned.SpaceReclaimed}, nil
+}
@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestValidatePruneOptions(t *testing.T) {
+	valid := []PruneOptions{
+		{},
+		{Labels: []string{"team=a"}},
+		{Types: []PruneType{PruneBuildCache}, Until: "24h"},
+		{Types: []PruneType{PruneImages, PruneVolumes}, Labels: []string{"!keep"}},
+	}
+	for _, opts := range valid {
+		if err := ValidatePruneOptions(opts); err != nil {
+			t.Errorf("ValidatePruneOptions(%+v) = %v, want nil", opts, err)
+		}
+	}
+
+	invalid := []PruneOptions{
+		{Types: []PruneType{"everything"}},
+		{Until: "yesterday"},
+		{Labels: []string{"=a"}},
+		{KeepLast: -1},
+		{Types: []PruneType{PruneImages, PruneBuildCache}, Labels: []string{"team=a"}},
+	}
+	for _, opts := range invalid {
+		var invalidInput *InvalidInputError
+		if err := ValidatePruneOptions(opts); !errors.As(err, &invalidInput) {
+			t.Errorf("ValidatePruneOptions(%+v) = %v, want InvalidInputError", opts, err)
+		}
+	}
+}
+
+func TestPruneKeepLastCountsImagesInUse(t *testing.T) {
+	daemon := &fakeDaemon{images: []*types.ImageSummary{
+		{ID: "app3", RepoTags: []string{"app:3"}, Created: 300, Containers: 1},
+		{ID: "app2", RepoTags: []string{"app:2"}, Created: 200},
+		{ID: "app1", RepoTags: []string{"app:1"}, Created: 100},
+		{ID: "db1", RepoTags: []string{"db:1"}, Created: 100},
+	}}
+	service := NewDockerServiceWithClient(daemon)
+
+	// app:3 is the newest and in use, so keeping 2 keeps app:3 and app:2
+	report, err := service.Prune(context.Background(), PruneOptions{Types: []PruneType{PruneImages}, KeepLast: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(daemon.calls); got != "[remove image app1]" {
+		t.Errorf("prune made calls %s, want only app1 removed", got)
+	}
+	if items := report.Results[0].Items; len(items) != 1 || items[0].ID != "app1" {
+		t.Errorf("report lists %+v, want app1", items)
+	}
+}
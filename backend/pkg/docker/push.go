@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// anonymousRegistryAuth is the encoded empty credential set. Some daemons
+// reject pushes that carry no X-Registry-Auth header at all.
+const anonymousRegistryAuth = "e30=" // base64("{}")
+
+// TagImage adds the target reference to the source image.
+func (s *DockerService) TagImage(ctx context.Context, source, target string) (string, error) {
+	if source == "" || target == "" {
+		return "", invalidInput("source and target images are required")
+	}
+
+	if err := s.cli.ImageTag(ctx, source, target); err != nil {
+		return "", notFound(err, "image", source)
+	}
+
+	return fmt.Sprintf("Image %s tagged as %s", source, target), nil
+}
+
+// UntagImage removes one reference from an image. The last reference of an
+// image is refused, since removing it would delete the image itself.
+func (s *DockerService) UntagImage(ctx context.Context, reference string) (string, error) {
+	imageInspect, _, err := s.cli.ImageInspectWithRaw(ctx, reference)
+	if err != nil {
+		return "", notFound(err, "image", reference)
+	}
+	if len(imageInspect.RepoTags) <= 1 {
+		return "", &ConflictError{Message: fmt.Sprintf("%s is the only tag of image %s, remove the image instead", reference, imageInspect.ID)}
+	}
+
+	if _, err := s.cli.ImageRemove(ctx, reference, types.ImageRemoveOptions{}); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Tag %s removed", reference), nil
+}
+
+// PushOptions controls how PushImage uploads an image.
+type PushOptions struct {
+	RegistryAuth string                        // Encoded credentials, see RegistryAuth.Encode
+	Progress     func(jsonmessage.JSONMessage) // Receives each progress message, if set
+}
+
+// PushImage uploads an image reference to its registry.
+func (s *DockerService) PushImage(ctx context.Context, reference string, opts PushOptions) (string, error) {
+	if opts.RegistryAuth == "" {
+		opts.RegistryAuth = anonymousRegistryAuth
+	}
+
+	reader, err := s.cli.ImagePush(ctx, reference, types.ImagePushOptions{RegistryAuth: opts.RegistryAuth})
+	if err != nil {
+		return "", fmt.Errorf("failed to push image: %w", Classify(err))
+	}
+	defer reader.Close()
+
+	if err := decodeProgress(reader, opts.Progress); err != nil {
+		return "", fmt.Errorf("failed to push image: %w", Classify(err))
+	}
+
+	return fmt.Sprintf("Image %s pushed successfully", reference), nil
+}
+
+// MirrorOptions carries the credentials of both registries in a mirror.
+type MirrorOptions struct {
+	SourceAuth string                        // Encoded credentials for the source registry
+	TargetAuth string                        // Encoded credentials for the target registry
+	Keep       bool                          // Leave the pulled source and the target tag on this host
+	Progress   func(jsonmessage.JSONMessage) // Receives each progress message, if set
+}
+
+// MirrorImage copies an image between registries: it pulls source, tags it as
+// target and pushes target. The source is always pulled so the latest version
+// is mirrored. Unless opts.Keep is set, a successful mirror then removes the
+// target tag and the source image again, except for any that were already on
+// this host; a failed one leaves them for a retry.
+func (s *DockerService) MirrorImage(ctx context.Context, source, target string, opts MirrorOptions) (string, error) {
+	_, _, err := s.cli.ImageInspectWithRaw(ctx, source)
+	hadSource := err == nil
+	_, _, err = s.cli.ImageInspectWithRaw(ctx, target)
+	hadTarget := err == nil
+
+	if _, err := s.PullImage(ctx, source, PullOptions{RegistryAuth: opts.SourceAuth, Progress: opts.Progress, Force: true}); err != nil {
+		return "", err
+	}
+	if _, err := s.TagImage(ctx, source, target); err != nil {
+		return "", err
+	}
+	if _, err := s.PushImage(ctx, target, PushOptions{RegistryAuth: opts.TargetAuth, Progress: opts.Progress}); err != nil {
+		return "", err
+	}
+
+	message := fmt.Sprintf("Image %s mirrored to %s", source, target)
+	if opts.Keep {
+		return message, nil
+	}
+
+	// Remove the target tag before the source, so the image goes with the last reference
+	for _, reference := range []struct {
+		name    string
+		existed bool
+	}{{target, hadTarget}, {source, hadSource}} {
+		if reference.existed {
+			continue
+		}
+		if _, err := s.cli.ImageRemove(ctx, reference.name, types.ImageRemoveOptions{PruneChildren: true}); err != nil {
+			message += fmt.Sprintf("; %s was left on the host: %v", reference.name, Classify(err))
+		}
+	}
+	return message, nil
+}
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestMirrorImageCleansUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		images []*types.ImageSummary
+		keep   bool
+		calls  string
+		tags   string // Tags left on the host
+	}{
+		{
+			name:  "removes what it pulled and tagged",
+			calls: "[pull src/app:1 tag src/app:1 dst/app:1 push dst/app:1 untag dst/app:1 remove image src/app:1]",
+			tags:  "[]",
+		},
+		{
+			name:  "keeps them when asked",
+			keep:  true,
+			calls: "[pull src/app:1 tag src/app:1 dst/app:1 push dst/app:1]",
+			tags:  "[src/app:1 dst/app:1]",
+		},
+		{
+			name:   "leaves a source that was already there",
+			images: []*types.ImageSummary{{ID: "sha256:app", RepoTags: []string{"src/app:1"}}},
+			calls:  "[pull src/app:1 tag src/app:1 dst/app:1 push dst/app:1 untag dst/app:1]",
+			tags:   "[src/app:1]",
+		},
+	}
+	for _, test := range tests {
+		daemon := &fakeDaemon{images: test.images}
+		service := NewDockerServiceWithClient(daemon)
+
+		if _, err := service.MirrorImage(context.Background(), "src/app:1", "dst/app:1", MirrorOptions{Keep: test.keep}); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := fmt.Sprint(daemon.calls); got != test.calls {
+			t.Errorf("%s: calls %s, want %s", test.name, got, test.calls)
+		}
+		tags := []string{}
+		for _, image := range daemon.images {
+			tags = append(tags, image.RepoTags...)
+		}
+		if got := fmt.Sprint(tags); got != test.tags {
+			t.Errorf("%s: tags left %s, want %s", test.name, got, test.tags)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"Docker_Management/pkg/config"
+
+	"github.com/docker/docker/api/types"
+)
+
+// RegistryAuth holds the credentials used to pull from or push to a registry.
+type RegistryAuth struct {
+	ServerAddress string `json:"server_address"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identity_token"`
+}
+
+// Encode returns the credentials in the form the daemon expects in the
+// X-Registry-Auth header, or "" when there are none.
+func (a RegistryAuth) Encode() (string, error) {
+	if a == (RegistryAuth{}) {
+		return "", nil
+	}
+
+	data, err := json.Marshal(types.AuthConfig{
+		Username:      a.Username,
+		Password:      a.Password,
+		ServerAddress: a.ServerAddress,
+		IdentityToken: a.IdentityToken,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// CredentialStore holds the named registry credential sets from the configuration.
+type CredentialStore struct {
+	credentials map[string]RegistryAuth
+}
+
+// NewCredentialStore indexes credentials by name.
+func NewCredentialStore(credentials []config.RegistryCredential) *CredentialStore {
+	store := &CredentialStore{credentials: map[string]RegistryAuth{}}
+	for _, credential := range credentials {
+		store.credentials[credential.Name] = RegistryAuth{
+			ServerAddress: credential.ServerAddress,
+			Username:      credential.Username,
+			Password:      credential.Password,
+			IdentityToken: credential.IdentityToken,
+		}
+	}
+	return store
+}
+
+// Get returns the named credential set.
+func (c *CredentialStore) Get(name string) (RegistryAuth, error) {
+	auth, ok := c.credentials[name]
+	if !ok {
+		return RegistryAuth{}, &NotFoundError{Kind: "registry credential", ID: name}
+	}
+	return auth, nil
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// LookupResource returns the names and labels of a container, image, volume or network.
+func (s *DockerService) LookupResource(ctx context.Context, resourceType PruneType, id string) (Resource, error) {
+	resource := Resource{Type: resourceType}
+	switch resourceType {
+	case PruneContainers:
+		container, err := s.cli.ContainerInspect(ctx, id)
+		if err != nil {
+			return resource, notFound(err, "container", id)
+		}
+		resource.ID = container.ID
+		resource.Names = []string{strings.TrimPrefix(container.Name, "/")}
+		if container.Config != nil {
+			resource.Labels = container.Config.Labels
+		}
+	case PruneImages:
+		image, _, err := s.cli.ImageInspectWithRaw(ctx, id)
+		if err != nil {
+			return resource, notFound(err, "image", id)
+		}
+		resource.ID = image.ID
+		resource.Names = image.RepoTags
+		if image.Config != nil {
+			resource.Labels = image.Config.Labels
+		}
+	case PruneVolumes:
+		volume, err := s.cli.VolumeInspect(ctx, id)
+		if err != nil {
+			return resource, notFound(err, "volume", id)
+		}
+		resource.ID = volume.Name
+		resource.Names = []string{volume.Name}
+		resource.Labels = volume.Labels
+	case PruneNetworks:
+		network, err := s.cli.NetworkInspect(ctx, id, types.NetworkInspectOptions{})
+		if err != nil {
+			return resource, notFound(err, "network", id)
+		}
+		resource.ID = network.ID
+		resource.Names = []string{network.Name}
+		resource.Labels = network.Labels
+	default:
+		return resource, invalidInput("unknown resource type: %s", resourceType)
+	}
+	return resource, nil
+}
+
+// ExecContainer returns the ID of the container an exec instance runs in.
+func (s *DockerService) ExecContainer(ctx context.Context, execID string) (string, error) {
+	inspect, err := s.cli.ContainerExecInspect(ctx, execID)
+	if err != nil {
+		return "", notFound(err, "exec instance", execID)
+	}
+	return inspect.ContainerID, nil
+}
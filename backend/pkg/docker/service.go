@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+
+	"Docker_Management/pkg/config"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// DockerService owns a single long-lived Docker client and exposes the
+// container, image, volume and network operations used by the API.
+type DockerService struct {
+	cli client.APIClient
+}
+
+// NewDockerService creates a DockerService connected to the daemon described by cfg.
+func NewDockerService(cfg config.DockerConfig) (*DockerService, error) {
+	var opts []client.Opt
+
+	// Load TLS material the same way the docker CLI does
+	if cfg.CertPath != "" {
+		tlsc, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             filepath.Join(cfg.CertPath, "ca.pem"),
+			CertFile:           filepath.Join(cfg.CertPath, "cert.pem"),
+			KeyFile:            filepath.Join(cfg.CertPath, "key.pem"),
+			InsecureSkipVerify: !cfg.TLSVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport:     &http.Transport{TLSClientConfig: tlsc},
+			CheckRedirect: client.CheckRedirect,
+		}))
+	}
+
+	if cfg.Host != "" {
+		opts = append(opts, client.WithHost(cfg.Host))
+	}
+
+	// Pin the API version if configured, otherwise negotiate it with the daemon
+	if cfg.APIVersion != "" {
+		opts = append(opts, client.WithVersion(cfg.APIVersion))
+	} else {
+		opts = append(opts, client.WithAPIVersionNegotiation())
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDockerServiceWithClient(cli), nil
+}
+
+// NewDockerServiceWithClient wraps an existing API client, such as an in-memory stub daemon.
+func NewDockerServiceWithClient(cli client.APIClient) *DockerService {
+	return &DockerService{cli: cli}
+}
+
+// Close releases the underlying Docker client.
+func (s *DockerService) Close() error {
+	return s.cli.Close()
+}
+
+// DaemonHost returns the address of the daemon the client talks to.
+func (s *DockerService) DaemonHost() string {
+	return s.cli.DaemonHost()
+}
+
+// Events subscribes to the daemon's event stream.
+func (s *DockerService) Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	return s.cli.Events(ctx, options)
+}
@@ -0,0 +1,265 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// StatsSample is one resource usage sample for a container.
+type StatsSample struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Read          time.Time `json:"read"`
+	CPUPercent    float64   `json:"cpu_usage_percent"`
+	OnlineCPUs    uint32    `json:"online_cpus"`
+	MemoryUsage   uint64    `json:"memory_usage_bytes"` // Excludes page cache
+	MemoryLimit   uint64    `json:"memory_limit_bytes"`
+	MemoryPercent float64   `json:"memory_usage_percent"`
+	NetworkRx     uint64    `json:"network_rx_bytes"`
+	NetworkTx     uint64    `json:"network_tx_bytes"`
+	BlockRead     uint64    `json:"block_read_bytes"`
+	BlockWrite    uint64    `json:"block_write_bytes"`
+	PIDs          uint64    `json:"pids"`
+}
+
+// newStatsSample derives a StatsSample from a raw daemon stats frame.
+func newStatsSample(containerID string, stats *types.StatsJSON) StatsSample {
+	memoryUsage := memoryUsageWithoutCache(stats.MemoryStats)
+	networkRx, networkTx := networkTotals(stats)
+	blockRead, blockWrite := blockIOTotals(stats.BlkioStats)
+
+	return StatsSample{
+		ID:            containerID,
+		Name:          strings.TrimPrefix(stats.Name, "/"),
+		Read:          stats.Read,
+		CPUPercent:    calculateCPUPercentage(stats.Stats),
+		OnlineCPUs:    onlineCPUs(stats.CPUStats),
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   stats.MemoryStats.Limit,
+		MemoryPercent: percentOf(float64(memoryUsage), float64(stats.MemoryStats.Limit)),
+		NetworkRx:     networkRx,
+		NetworkTx:     networkTx,
+		BlockRead:     blockRead,
+		BlockWrite:    blockWrite,
+		PIDs:          stats.PidsStats.Current,
+	}
+}
+
+// calculateCPUPercentage computes CPU usage from the delta between the current
+// and previous samples, scaled by the number of online CPUs.
+func calculateCPUPercentage(stats types.Stats) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	return cpuDelta / systemDelta * float64(onlineCPUs(stats.CPUStats)) * 100
+}
+
+// onlineCPUs falls back to the per-CPU usage list on daemons that do not report online_cpus.
+func onlineCPUs(cpu types.CPUStats) uint32 {
+	if cpu.OnlineCPUs > 0 {
+		return cpu.OnlineCPUs
+	}
+	return uint32(len(cpu.CPUUsage.PercpuUsage))
+}
+
+// memoryUsageWithoutCache subtracts reclaimable page cache the same way the docker CLI does.
+func memoryUsageWithoutCache(mem types.MemoryStats) uint64 {
+	// cgroup v1
+	if v, ok := mem.Stats["total_inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	// cgroup v2
+	if v, ok := mem.Stats["inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	return mem.Usage
+}
+
+func networkTotals(stats *types.StatsJSON) (rx, tx uint64) {
+	for _, network := range stats.Networks {
+		rx += network.RxBytes
+		tx += network.TxBytes
+	}
+	return rx, tx
+}
+
+func blockIOTotals(blkio types.BlkioStats) (read, write uint64) {
+	for _, entry := range blkio.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return read, write
+}
+
+// percentOf returns part as a percentage of total, or 0 when total is 0.
+func percentOf(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return part / total * 100
+}
+
+// SampleContainerStats takes a single stats sample. The daemon waits for a
+// second reading so that the CPU delta is meaningful.
+func (s *DockerService) SampleContainerStats(ctx context.Context, containerID string) (StatsSample, error) {
+	stats, err := s.cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return StatsSample{}, notFound(err, "container", containerID)
+	}
+	defer stats.Body.Close()
+
+	var stat types.StatsJSON
+	if err := json.NewDecoder(stats.Body).Decode(&stat); err != nil {
+		return StatsSample{}, err
+	}
+
+	return newStatsSample(containerID, &stat), nil
+}
+
+// StreamContainerStats follows the stats of the given containers, or of every
+// running container when none are given, and calls emit with the latest sample
+// of each container every interval until ctx is cancelled or emit fails.
+// A container drops out of the samples once it stops. Without given containers,
+// those started later join in; with them, the stream ends once all have stopped.
+func (s *DockerService) StreamContainerStats(ctx context.Context, containerIDs []string, interval time.Duration, emit func([]StatsSample) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	var mu sync.Mutex
+	var following []string // In the order the streams were opened
+	latest := map[string]StatsSample{}
+
+	// unfollow forgets a container whose stats stream has ended
+	unfollow := func(containerID string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delete(latest, containerID)
+		for i, id := range following {
+			if id == containerID {
+				following = append(following[:i], following[i+1:]...)
+				break
+			}
+		}
+	}
+
+	// Keep one daemon stats stream open per container and remember its latest frame
+	follow := func(containerID string) error {
+		mu.Lock()
+		for _, id := range following {
+			if id == containerID {
+				mu.Unlock()
+				return nil
+			}
+		}
+		following = append(following, containerID)
+		mu.Unlock()
+
+		stats, err := s.cli.ContainerStats(ctx, containerID, true)
+		if err != nil {
+			unfollow(containerID)
+			return notFound(err, "container", containerID)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer stats.Body.Close()
+			// The daemon ends the stream when the container stops
+			defer unfollow(containerID)
+
+			decoder := json.NewDecoder(stats.Body)
+			for {
+				var stat types.StatsJSON
+				if err := decoder.Decode(&stat); err != nil {
+					return
+				}
+				sample := newStatsSample(containerID, &stat)
+
+				mu.Lock()
+				latest[containerID] = sample
+				mu.Unlock()
+			}
+		}()
+		return nil
+	}
+
+	var started <-chan events.Message
+	var eventErrs <-chan error
+	if len(containerIDs) == 0 {
+		// Subscribe before listing, so no container starts unseen in between
+		args := filters.NewArgs(filters.Arg("type", "container"), filters.Arg("event", "start"))
+		started, eventErrs = s.cli.Events(ctx, types.EventsOptions{Filters: args})
+
+		containers, err := s.ListContainers(ctx, filters.NewArgs(), false)
+		if err != nil {
+			return err
+		}
+		for _, container := range containers {
+			// A container that stopped since the list is simply not followed
+			follow(container.ID)
+		}
+	} else {
+		for _, containerID := range containerIDs {
+			if err := follow(containerID); err != nil {
+				return err
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-started:
+			follow(event.Actor.ID)
+		case <-eventErrs:
+			// Keep sampling the containers already followed
+			started, eventErrs = nil, nil
+		case <-ticker.C:
+			samples := []StatsSample{}
+			mu.Lock()
+			remaining := len(following)
+			for _, containerID := range following {
+				if sample, ok := latest[containerID]; ok {
+					samples = append(samples, sample)
+				}
+			}
+			mu.Unlock()
+
+			if len(containerIDs) > 0 && remaining == 0 {
+				return nil
+			}
+			// Send an empty set once, so clients see the last container go
+			if len(samples) == 0 && sent == 0 {
+				continue
+			}
+			if err := emit(samples); err != nil {
+				return err
+			}
+			sent = len(samples)
+		}
+	}
+}
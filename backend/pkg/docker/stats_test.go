@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+)
+
+// statsClient is a daemon whose containers stream stats until the test stops them.
+type statsClient struct {
+	client.APIClient
+
+	mu      sync.Mutex
+	running []string
+	streams map[string]*io.PipeWriter
+	events  chan events.Message
+}
+
+func newStatsClient(running ...string) *statsClient {
+	return &statsClient{running: running, streams: map[string]*io.PipeWriter{}, events: make(chan events.Message)}
+}
+
+func (c *statsClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	containers := []types.Container{}
+	for _, id := range c.running {
+		containers = append(containers, types.Container{ID: id})
+	}
+	return containers, nil
+}
+
+func (c *statsClient) Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	return c.events, make(chan error)
+}
+
+// ContainerStats streams a frame every few milliseconds until the container is stopped.
+func (c *statsClient) ContainerStats(ctx context.Context, containerID string, stream bool) (types.ContainerStats, error) {
+	reader, writer := io.Pipe()
+	c.mu.Lock()
+	c.streams[containerID] = writer
+	c.mu.Unlock()
+
+	go func() {
+		encoder := json.NewEncoder(writer)
+		for {
+			if err := encoder.Encode(types.StatsJSON{Name: "/" + containerID}); err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				writer.Close()
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}()
+	return types.ContainerStats{Body: reader}, nil
+}
+
+func (c *statsClient) stop(containerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams[containerID].Close()
+}
+
+func (c *statsClient) start(containerID string) {
+	c.events <- events.Message{Type: "container", Action: "start", Actor: events.Actor{ID: containerID}}
+}
+
+func TestStreamContainerStatsFollowsStartsAndStops(t *testing.T) {
+	cli := newStatsClient("aaa")
+	service := NewDockerServiceWithClient(cli)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Each step runs once the stream shows the containers before it
+	steps := []struct {
+		want string
+		then func()
+	}{
+		{"aaa", func() { go cli.start("bbb") }},
+		{"aaa,bbb", func() { cli.stop("aaa") }},
+		{"bbb", func() { cli.stop("bbb") }},
+		{"", cancel},
+	}
+	step := 0
+	err := service.StreamContainerStats(ctx, nil, 10*time.Millisecond, func(samples []StatsSample) error {
+		ids := []string{}
+		for _, sample := range samples {
+			ids = append(ids, sample.ID)
+		}
+		sort.Strings(ids)
+		if step < len(steps) && strings.Join(ids, ",") == steps[step].want {
+			steps[step].then()
+			step++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step != len(steps) {
+		t.Errorf("the stream never showed %q", steps[step].want)
+	}
+}
+
+func TestStreamContainerStatsEndsWhenGivenContainersStop(t *testing.T) {
+	cli := newStatsClient()
+	service := NewDockerServiceWithClient(cli)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stopped := false
+	err := service.StreamContainerStats(ctx, []string{"aaa"}, 10*time.Millisecond, func(samples []StatsSample) error {
+		if !stopped {
+			stopped = true
+			cli.stop("aaa")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Error("the stream outlived the only container it followed")
+	}
+}
@@ -2,64 +2,42 @@ package docker
 
 import (
 	"context"
-	"errors"
-	"fmt"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters" // Importing filters package
-	"github.com/docker/docker/client"
 )
 
-// ListVolumes retrieves all Docker volumes on the system
-func ListVolumes() ([]*types.Volume, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// ListVolumes retrieves the Docker volumes matching filter
+func (s *DockerService) ListVolumes(ctx context.Context, filter filters.Args) ([]*types.Volume, error) {
+	volumeList, err := s.cli.VolumeList(ctx, filter)
 	if err != nil {
-		return nil, err
-	}
-
-	// List volumes (no filters applied here)
-	volumeList, err := cli.VolumeList(context.Background(), filters.Args{})
-	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	// Return the volume list, which contains pointers to Volume objects
 	return volumeList.Volumes, nil
 }
 
-func InspectVolume(volumeName string) (*types.Volume, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+func (s *DockerService) InspectVolume(ctx context.Context, volumeName string) (*types.Volume, error) {
 	// Inspect the volume using its name
-	volume, err := cli.VolumeInspect(context.Background(), volumeName)
+	volume, err := s.cli.VolumeInspect(ctx, volumeName)
 	if err != nil {
-		return nil, fmt.Errorf("error inspecting volume: %v", err)
+		return nil, notFound(err, "volume", volumeName)
 	}
 
 	// Return the volume details
 	return &volume, nil
 }
 
-func ListContainersAttachedToVolume(volumeName string) ([]string, error) {
-	// Create a Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
-	if err != nil {
-		return nil, err
-	}
-
+func (s *DockerService) ListContainersAttachedToVolume(ctx context.Context, volumeName string) ([]string, error) {
 	// List all containers (including stopped ones)
-	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{All: true})
+	containers, err := s.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 	if err != nil {
 		return nil, err
 	}
 
 	// Iterate through containers and check if they are using the specified volume
-	var containerIDs []string
+	containerIDs := []string{}
 	for _, container := range containers {
 		for _, mount := range container.Mounts {
 			if mount.Name == volumeName {
@@ -68,34 +46,27 @@ func ListContainersAttachedToVolume(volumeName string) ([]string, error) {
 		}
 	}
 
-	if len(containerIDs) == 0 {
-		return nil, fmt.Errorf("no containers attached to volume: %s", volumeName)
-	}
-
 	return containerIDs, nil
 }
 
-func RemoveVolume(volumeName string) (string, error) {
-	// Create a new Docker client
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.41"))
+// RemoveVolume force-removes a volume unless protection refuses it
+func (s *DockerService) RemoveVolume(ctx context.Context, volumeName string, protection *ProtectionPolicy) (string, error) {
+	// Check if the volume exists before attempting to remove it
+	volume, err := s.cli.VolumeInspect(ctx, volumeName)
 	if err != nil {
-		return "", err
+		return "", notFound(err, "volume", volumeName)
 	}
 
-	// Check if the volume exists before attempting to remove it
-	volume, err := cli.VolumeInspect(context.Background(), volumeName)
-	if err != nil {
-		if client.IsErrNotFound(err) {
-			return "", errors.New("Invalid Volume Name: Volume not found")
-		}
-		return "", err // Return other errors
+	// Refuse to remove a protected volume
+	if err := protection.Check(Resource{Type: PruneVolumes, ID: volume.Name, Names: []string{volume.Name}, Labels: volume.Labels}); err != nil {
+		return "", err
 	}
 
 	// Attempt to remove the volume
-	err = cli.VolumeRemove(context.Background(), volume.Name, true) // true = force remove
+	err = s.cli.VolumeRemove(ctx, volume.Name, true) // true = force remove
 	if err != nil {
 		return "", err // Return any error encountered
 	}
 
 	return "Volume removed successfully", nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// AuditEntry records one mutating API call.
+type AuditEntry struct {
+	Timestamp  time.Time              `json:"timestamp" bson:"timestamp"`
+	RequestID  string                 `json:"request_id,omitempty" bson:"request_id,omitempty"` // Matches the access log and the X-Request-ID response header
+	Principal  string                 `json:"principal" bson:"principal"`                       // User or API token; the client address when authentication is disabled
+	Role       string                 `json:"role,omitempty" bson:"role,omitempty"`
+	ClientAddr string                 `json:"client_addr" bson:"client_addr"`
+	Method     string                 `json:"method" bson:"method"`
+	Route      string                 `json:"route" bson:"route"` // Route template, e.g. /volumes/remove
+	Host       string                 `json:"host" bson:"host"`   // Docker host the call was made against
+	TargetType string                 `json:"target_type,omitempty" bson:"target_type,omitempty"`
+	Targets    []string               `json:"targets,omitempty" bson:"targets,omitempty"` // IDs or names as given in the request
+	Params     map[string]interface{} `json:"params,omitempty" bson:"params,omitempty"`   // Query parameters and JSON body, with secrets redacted
+	Status     int                    `json:"status" bson:"status"`
+	Outcome    string                 `json:"outcome" bson:"outcome"`
+	Error      string                 `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms" bson:"duration_ms"`
+}
+
+// AuditFilter selects audit entries. Zero-valued fields match everything.
+type AuditFilter struct {
+	Principal string
+	Method    string
+	Route     string
+	Host      string
+	Target    string // Matches entries naming a target that starts with it
+	Outcome   string
+	Since     time.Time
+	Until     time.Time
+	Skip      int64
+	Limit     int64
+}
@@ -1,7 +0,0 @@
-package models
-
-type Container struct {
-    ID    string   `json:"id"`
-    Names []string `json:"names"`
-}
-
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// History record sources
+const (
+	HistorySourceAPI    = "api"    // Operation performed through this backend
+	HistorySourceDaemon = "daemon" // Event observed on the Docker daemon
+)
+
+// History record outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// HistoryRecord is one container or image lifecycle entry.
+type HistoryRecord struct {
+	Host           string    `json:"host" bson:"host"`
+	Action         string    `json:"action" bson:"action"` // start, stop, remove, pull, prune, or a daemon event action
+	ContainerID    string    `json:"container_id,omitempty" bson:"container_id,omitempty"`
+	ContainerNames []string  `json:"container_names,omitempty" bson:"container_names,omitempty"`
+	Image          string    `json:"image,omitempty" bson:"image,omitempty"`
+	Actor          string    `json:"actor" bson:"actor"`
+	Source         string    `json:"source" bson:"source"`
+	Timestamp      time.Time `json:"timestamp" bson:"timestamp"`
+	Outcome        string    `json:"outcome" bson:"outcome"`
+	Message        string    `json:"message,omitempty" bson:"message,omitempty"`
+}
+
+// HistoryFilter selects history records. Zero-valued fields match everything.
+type HistoryFilter struct {
+	Host        string
+	Action      string
+	ContainerID string
+	Image       string
+	Actor       string
+	Source      string
+	Outcome     string
+	Since       time.Time
+	Until       time.Time
+	Skip        int64
+	Limit       int64
+}